@@ -0,0 +1,385 @@
+package rf
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Antenna radiation patterns
+
+// PatternSample is a single gain sample in an antenna pattern cut
+type PatternSample struct {
+	// Angle in degrees
+	Angle float64
+	// Gain in dB relative to the pattern peak gain
+	Gain float64
+}
+
+// AntennaPattern describes the radiation pattern of an antenna as a peak gain with
+// horizontal (azimuth) and vertical (elevation) cuts relative to that peak.
+// Empty cuts are treated as omnidirectional in that plane.
+type AntennaPattern struct {
+	// Gain is the peak (boresight) gain in dBi
+	Gain float64
+	// Horizontal holds relative gain samples by azimuth, 0 degrees being boresight
+	Horizontal []PatternSample
+	// Vertical holds relative gain samples by elevation, 0 degrees being the horizon and +ve up
+	Vertical []PatternSample
+}
+
+// NewIsotropicPattern creates an ideal isotropic antenna pattern (0 dBi in all directions)
+func NewIsotropicPattern() *AntennaPattern {
+	return &AntennaPattern{}
+}
+
+// GainAt calculates the gain in dBi at the provided azimuth and elevation (degrees relative to boresight)
+// by linearly interpolating each pattern cut and combining them with the peak gain
+func (p *AntennaPattern) GainAt(azimuthDeg, elevationDeg float64) float64 {
+	return p.Gain + interpolatePatternCut(p.Horizontal, azimuthDeg) + interpolatePatternCut(p.Vertical, elevationDeg)
+}
+
+// interpolatePatternCut linearly interpolates a pattern cut at the provided angle, wrapping around 360 degrees
+func interpolatePatternCut(samples []PatternSample, angle float64) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+
+	angle = math.Mod(angle, 360)
+	if angle < 0 {
+		angle += 360
+	}
+
+	// Find the first sample above the requested angle
+	i := sort.Search(len(samples), func(i int) bool { return samples[i].Angle > angle })
+
+	lower, upper := samples[len(samples)-1], samples[0]
+	lower.Angle -= 360
+	if i > 0 {
+		lower = samples[i-1]
+	}
+	if i < len(samples) {
+		upper = samples[i]
+	} else {
+		upper.Angle += 360
+	}
+
+	if upper.Angle == lower.Angle {
+		return lower.Gain
+	}
+
+	return lower.Gain + (upper.Gain-lower.Gain)*(angle-lower.Angle)/(upper.Angle-lower.Angle)
+}
+
+// normalisePatternCut wraps angles into [0, 360) and sorts a pattern cut for interpolation
+func normalisePatternCut(samples []PatternSample) {
+	for i := range samples {
+		samples[i].Angle = math.Mod(samples[i].Angle, 360)
+		if samples[i].Angle < 0 {
+			samples[i].Angle += 360
+		}
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].Angle < samples[j].Angle })
+}
+
+// LoadPatternCSV loads a horizontal antenna pattern from a 2-column (angle, gain dBi) CSV file
+func LoadPatternCSV(path string) (*AntennaPattern, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParsePatternCSV(f)
+}
+
+// ParsePatternCSV parses a horizontal antenna pattern from 2-column (angle, gain dBi) CSV data
+func ParsePatternCSV(r io.Reader) (*AntennaPattern, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make([]PatternSample, 0, len(records))
+	peak := math.Inf(-1)
+
+	for i, record := range records {
+		angle, err := strconv.ParseFloat(strings.TrimSpace(record[0]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid pattern angle on line %d (%s)", i+1, record[0])
+		}
+		gain, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid pattern gain on line %d (%s)", i+1, record[1])
+		}
+
+		samples = append(samples, PatternSample{Angle: angle, Gain: gain})
+		peak = math.Max(peak, gain)
+	}
+
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("Antenna pattern must contain at least one sample")
+	}
+
+	for i := range samples {
+		samples[i].Gain -= peak
+	}
+	normalisePatternCut(samples)
+
+	return &AntennaPattern{Gain: peak, Horizontal: samples}, nil
+}
+
+// dBdToDBi is the gain of a half-wave dipole over an isotropic radiator
+const dBdToDBi = 2.15
+
+// LoadMSIPlanet loads an antenna pattern from a Planet/MSI (.msi) pattern file
+func LoadMSIPlanet(path string) (*AntennaPattern, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseMSIPlanet(f)
+}
+
+// ParseMSIPlanet parses an antenna pattern in the Planet/MSI format.
+// The GAIN header is read as dBd unless suffixed with dBi, and the HORIZONTAL and VERTICAL
+// sections contain attenuation below the peak gain by angle. Vertical angles in MSI files
+// increase below the horizon, so are inverted to match the elevation convention of GainAt.
+func ParseMSIPlanet(r io.Reader) (*AntennaPattern, error) {
+	pattern := AntennaPattern{}
+	scanner := bufio.NewScanner(r)
+
+	var section *[]PatternSample
+	var remaining int
+	line := 0
+
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		// Pattern section data
+		if section != nil && remaining > 0 {
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("Invalid MSI pattern sample on line %d (%s)", line, scanner.Text())
+			}
+			angle, err := strconv.ParseFloat(fields[0], 64)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid MSI pattern angle on line %d (%s)", line, fields[0])
+			}
+			loss, err := strconv.ParseFloat(fields[1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid MSI pattern attenuation on line %d (%s)", line, fields[1])
+			}
+
+			if section == &pattern.Vertical {
+				angle = -angle
+			}
+
+			*section = append(*section, PatternSample{Angle: angle, Gain: -loss})
+			remaining--
+			continue
+		}
+
+		// Header keywords
+		switch strings.ToUpper(fields[0]) {
+		case "GAIN":
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("Missing MSI gain value on line %d", line)
+			}
+			gain, err := strconv.ParseFloat(fields[1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid MSI gain on line %d (%s)", line, fields[1])
+			}
+			if len(fields) < 3 || !strings.EqualFold(fields[2], "dBi") {
+				gain += dBdToDBi
+			}
+			pattern.Gain = gain
+
+		case "HORIZONTAL", "VERTICAL":
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("Missing MSI sample count on line %d", line)
+			}
+			count, err := strconv.Atoi(fields[1])
+			if err != nil || count < 0 {
+				return nil, fmt.Errorf("Invalid MSI sample count on line %d (%s)", line, fields[1])
+			}
+			section, remaining = &pattern.Horizontal, count
+			if strings.EqualFold(fields[0], "VERTICAL") {
+				section = &pattern.Vertical
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if remaining > 0 {
+		return nil, fmt.Errorf("MSI pattern ended with %d samples missing", remaining)
+	}
+
+	normalisePatternCut(pattern.Horizontal)
+	normalisePatternCut(pattern.Vertical)
+
+	return &pattern, nil
+}
+
+// DowntiltCoverageRadius calculates the ground distances at which the half power edges of a downtilted main beam
+// intersect (flat) ground for an antenna at heightM meters. Where the upper beam edge is at or above the horizon
+// the outer edge is infinite.
+func DowntiltCoverageRadius(heightM float64, downtiltDeg float64, beamwidthDeg float64) (innerEdge, outerEdge Distance) {
+	lower := (downtiltDeg + beamwidthDeg/2) / 180 * π
+	upper := (downtiltDeg - beamwidthDeg/2) / 180 * π
+
+	innerEdge = Distance(heightM / math.Tan(lower))
+	outerEdge = Distance(math.Inf(1))
+	if upper > 0 {
+		outerEdge = Distance(heightM / math.Tan(upper))
+	}
+
+	return innerEdge, outerEdge
+}
+
+// OptimalDowntilt calculates the downtilt (degrees below the horizon) placing the centre of the ground area
+// illuminated by a beam of the provided half power beamwidth at targetRadius, ie. midway between the
+// DowntiltCoverageRadius edges. Returns NaN for non-positive target radii.
+func OptimalDowntilt(heightM float64, beamwidthDeg float64, targetRadius Distance) float64 {
+	tilt, err := OptimalDowntiltWithOptions(heightM, beamwidthDeg, targetRadius, DefaultSolverOptions)
+	if err != nil {
+		return math.NaN()
+	}
+	return tilt
+}
+
+// downtiltHorizonOffset is the offset in degrees beyond the tilt placing the upper beam edge on the horizon at which
+// downtilt searches start, where the footprint would otherwise extend to infinity
+const downtiltHorizonOffset = 1e-9
+
+// OptimalDowntiltWithOptions calculates the optimal downtilt as OptimalDowntilt, using the provided solver options
+// with the tolerance in degrees, returning an error for non-positive target radii or where the search fails
+func OptimalDowntiltWithOptions(heightM float64, beamwidthDeg float64, targetRadius Distance, opts SolverOptions) (float64, error) {
+	if targetRadius <= 0 {
+		return 0, fmt.Errorf("Target radius must be positive (radius: %.2fm)", targetRadius)
+	}
+
+	// The footprint centre moves monotonically inwards from the horizon as the beam is tilted towards nadir
+	centre := func(tilt float64) float64 {
+		inner, outer := DowntiltCoverageRadius(heightM, tilt, beamwidthDeg)
+		return float64((inner+outer)/2 - targetRadius)
+	}
+
+	// Start just below the tilt at which the upper edge reaches the horizon
+	lo := beamwidthDeg/2 + downtiltHorizonOffset
+	tilt, err := Brent(centre, lo, 90, opts)
+	if err != nil {
+		return 0, fmt.Errorf("Downtilt search failed: %w", err)
+	}
+
+	return tilt, nil
+}
+
+// BeamFootprint approximates the (flat) ground area illuminated within the half power beamwidths of a directional
+// antenna at heightM meters with the provided downtilt below the horizon (90 degrees for nadir). The footprint is
+// treated as an ellipse spanning the DowntiltCoverageRadius edges along the boresight azimuth, with the cross
+// range width of the azimuth beam at the boresight slant range. The centroid is the ground distance from the base
+// of the antenna to the centre of the footprint. Where the beam reaches the horizon the footprint is infinite.
+func BeamFootprint(heightM float64, azBeamwidthDeg, elBeamwidthDeg, downtiltDeg float64) (areaM2 float64, centroid Distance) {
+	inner, outer := DowntiltCoverageRadius(heightM, downtiltDeg, elBeamwidthDeg)
+	if math.IsInf(float64(outer), 1) {
+		return math.Inf(1), Distance(math.Inf(1))
+	}
+
+	// Boresight slant range and cross range width
+	slant := heightM / math.Sin(downtiltDeg/180*π)
+	width := 2 * slant * math.Tan(azBeamwidthDeg/2/180*π)
+
+	length := float64(outer - inner)
+
+	return π / 4 * length * width, (inner + outer) / 2
+}
+
+// ApplyPatterns corrects a received power calculated for isotropic antennas (dBm) for the transmit and receive
+// antenna patterns, given the angles (degrees relative to each antenna's boresight) of the path at each end.
+// Nil patterns are treated as isotropic.
+func ApplyPatterns(rxPowerIsotropicDBm float64, txPat, rxPat *AntennaPattern, txAz, txEl, rxAz, rxEl float64) float64 {
+	power := rxPowerIsotropicDBm
+	if txPat != nil {
+		power += txPat.GainAt(txAz, txEl)
+	}
+	if rxPat != nil {
+		power += rxPat.GainAt(rxAz, rxEl)
+	}
+	return power
+}
+
+// BoresightOffset calculates the azimuth of each site relative to the other site's antenna boresight
+// (degrees clockwise from true north), ie. how far each antenna is from pointing at the other, in the range
+// (-180, 180] for use with AntennaPattern.GainAt and ApplyPatterns
+func BoresightOffset(site1, site2 Coordinate, site1BoresightDeg, site2BoresightDeg float64) (az1, az2 float64) {
+	bearing1 := CalculateBearing(site1.Lat, site1.Lon, site2.Lat, site2.Lon)
+	bearing2 := CalculateBearing(site2.Lat, site2.Lon, site1.Lat, site1.Lon)
+
+	return wrapAngle(bearing1 - site1BoresightDeg), wrapAngle(bearing2 - site2BoresightDeg)
+}
+
+// wrapAngle wraps an angle in degrees into the range (-180, 180]
+func wrapAngle(angle float64) float64 {
+	angle = math.Mod(angle, 360)
+	if angle > 180 {
+		angle -= 360
+	} else if angle <= -180 {
+		angle += 360
+	}
+	return angle
+}
+
+// IsolationGeometry is the angle in degrees above the horizontal of the line between two antennas
+type IsolationGeometry float64
+
+// Common antenna separation geometries
+const (
+	// IsolationHorizontal is side by side separation
+	IsolationHorizontal IsolationGeometry = 0
+	// IsolationVertical is vertically stacked (collinear) separation
+	IsolationVertical IsolationGeometry = 90
+)
+
+// AntennaIsolation estimates the coupling loss between two dipole antennas with the provided separation and
+// geometry, using the empirical horizontal (22 + 20log(d/λ)) and vertical (28 + 40log(d/λ)) separation formulas,
+// linearly weighted by angle for slant separations. For directional antennas, the gain of each antenna in the
+// direction of the other should be subtracted from the horizontal isolation.
+func AntennaIsolation(separation Distance, freq Frequency, orientation IsolationGeometry) Attenuation {
+	x := math.Log10(float64(separation) / float64(FrequencyToWavelength(freq)))
+	k := float64(orientation) / 90
+
+	horizontal := 22 + 20*x
+	vertical := 28 + 40*x
+
+	return Attenuation(horizontal + (vertical-horizontal)*k)
+}
+
+// SeparationForIsolation calculates the antenna separation required for the target isolation in dB with the
+// provided geometry, inverting AntennaIsolation
+func SeparationForIsolation(targetIsolationDB float64, freq Frequency, orientation IsolationGeometry) Distance {
+	k := float64(orientation) / 90
+
+	// Isolation is 22 + 6k + 20(1+k)·log(d/λ)
+	x := (targetIsolationDB - 22 - 6*k) / (20 * (1 + k))
+
+	return Distance(math.Pow(10, x)) * Distance(FrequencyToWavelength(freq))
+}
@@ -0,0 +1,258 @@
+package rf
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// cardioidCSV generates a synthetic cardioid pattern with a 6 dBi peak in 10 degree steps
+func cardioidCSV() string {
+	buffer := bytes.NewBuffer([]byte{})
+	buffer.WriteString("# angle, gain (dBi)\n")
+	for angle := 0; angle < 360; angle += 10 {
+		field := math.Max((1+math.Cos(float64(angle)/180*math.Pi))/2, 0.01)
+		fmt.Fprintf(buffer, "%d, %.6f\n", angle, 6+20*math.Log10(field))
+	}
+	return buffer.String()
+}
+
+func TestAntennaPatterns(t *testing.T) {
+
+	t.Run("Isotropic patterns have unity gain in all directions", func(t *testing.T) {
+		p := NewIsotropicPattern()
+		for _, angle := range []float64{0, 45, 90, 180, 270, -90} {
+			assert.InDelta(t, 0.0, p.GainAt(angle, angle/2), allowedError)
+		}
+	})
+
+	t.Run("Can load a cardioid pattern from CSV", func(t *testing.T) {
+		p, err := ParsePatternCSV(bytes.NewBufferString(cardioidCSV()))
+		assert.Nil(t, err)
+		assert.Len(t, p.Horizontal, 36)
+
+		assert.InDelta(t, 6.0, p.GainAt(0, 0), allowedError)
+		assert.InDelta(t, 6.0-6.02, p.GainAt(90, 0), 0.01)
+		assert.InDelta(t, 6.0-6.02, p.GainAt(-90, 0), 0.01)
+		assert.InDelta(t, 6.0-40, p.GainAt(180, 0), allowedError)
+
+		// Interpolated between 0 and 10 degree samples
+		assert.InDelta(t, (p.GainAt(0, 0)+p.GainAt(10, 0))/2, p.GainAt(5, 0), allowedError)
+		assert.InDelta(t, (p.GainAt(350, 0)+p.GainAt(0, 0))/2, p.GainAt(355, 0), allowedError)
+		assert.InDelta(t, p.GainAt(355, 0), p.GainAt(-5, 0), allowedError)
+	})
+
+	t.Run("Rejects malformed pattern CSVs", func(t *testing.T) {
+		_, err := ParsePatternCSV(bytes.NewBufferString("0, 1.0\n10, abc\n"))
+		assert.NotNil(t, err)
+
+		_, err = ParsePatternCSV(bytes.NewBufferString("0, 1.0, 2.0\n"))
+		assert.NotNil(t, err)
+
+		_, err = ParsePatternCSV(bytes.NewBufferString(""))
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Can load a Planet/MSI pattern file", func(t *testing.T) {
+		p, err := LoadMSIPlanet("testdata/sample.msi")
+		assert.Nil(t, err)
+		assert.Len(t, p.Horizontal, 36)
+		assert.Len(t, p.Vertical, 36)
+
+		assert.InDelta(t, 14.0, p.GainAt(0, 0), allowedError)
+		assert.InDelta(t, 13.2, p.GainAt(15, 0), allowedError)
+		assert.InDelta(t, 11.0, p.GainAt(-30, 0), allowedError)
+		assert.InDelta(t, 11.0, p.GainAt(0, -10), allowedError)
+		assert.InDelta(t, 8.0, p.GainAt(30, -10), allowedError)
+		assert.InDelta(t, 14.0-25.0, p.GainAt(180, 0), allowedError)
+	})
+
+	t.Run("Reads MSI gain in dBd unless specified", func(t *testing.T) {
+		p, err := ParseMSIPlanet(bytes.NewBufferString("NAME TEST\nGAIN 10\nHORIZONTAL 2\n0 0\n180 10\n"))
+		assert.Nil(t, err)
+		assert.InDelta(t, 12.15, p.GainAt(0, 0), allowedError)
+		assert.InDelta(t, 7.15, p.GainAt(90, 0), allowedError)
+	})
+
+	t.Run("Rejects truncated MSI patterns", func(t *testing.T) {
+		_, err := ParseMSIPlanet(bytes.NewBufferString("GAIN 10 dBi\nHORIZONTAL 4\n0 0\n90 3\n"))
+		assert.NotNil(t, err)
+
+		_, err = LoadMSIPlanet("testdata/missing.msi")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Can calculate downtilt coverage edges", func(t *testing.T) {
+		tests := []struct {
+			name             string
+			height, tilt, bw float64
+			inner, outer     float64
+		}{
+			{"Macro cell 30m with 6 degree tilt", 30, 6, 10, 154.34, 1718.70},
+			{"Macro cell 45m with 8 degree tilt", 45, 8, 7, 221.18, 571.78},
+			{"Rooftop 20m with 4 degree tilt", 20, 4, 6, 162.89, 1145.80},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				inner, outer := DowntiltCoverageRadius(test.height, test.tilt, test.bw)
+				assert.InDelta(t, test.inner, float64(inner), 0.01)
+				assert.InDelta(t, test.outer, float64(outer), 0.01)
+			})
+		}
+	})
+
+	t.Run("Downtilt coverage is unbounded where the beam reaches the horizon", func(t *testing.T) {
+		_, outer := DowntiltCoverageRadius(30, 3, 10)
+		assert.True(t, math.IsInf(float64(outer), 1))
+	})
+
+	t.Run("Isotropic patterns do not change received power", func(t *testing.T) {
+		iso := NewIsotropicPattern()
+		assert.InDelta(t, -80.0, ApplyPatterns(-80.0, iso, iso, 45, 10, 120, -5), allowedError)
+		assert.InDelta(t, -80.0, ApplyPatterns(-80.0, nil, nil, 45, 10, 120, -5), allowedError)
+	})
+
+	t.Run("Directional patterns apply gain by angle", func(t *testing.T) {
+		iso := NewIsotropicPattern()
+		sector, err := LoadMSIPlanet("testdata/sample.msi")
+		assert.Nil(t, err)
+
+		// Boresight applies the peak gain
+		assert.InDelta(t, -80.0+14.0, ApplyPatterns(-80.0, sector, iso, 0, 0, 0, 0), allowedError)
+
+		// Off boresight at both ends
+		assert.InDelta(t, -80.0+11.0+11.0, ApplyPatterns(-80.0, sector, sector, 30, 0, -30, 0), allowedError)
+		assert.InDelta(t, -80.0+14.0-25.0, ApplyPatterns(-80.0, iso, sector, 0, 0, 180, 0), allowedError)
+	})
+
+	t.Run("Boresight offset is zero for antennas pointing at each other", func(t *testing.T) {
+		site1 := Coordinate{Lat: -36.8485, Lon: 174.7633}
+		site2 := Coordinate{Lat: -36.7485, Lon: 174.8633}
+
+		bearing1 := CalculateBearing(site1.Lat, site1.Lon, site2.Lat, site2.Lon)
+		bearing2 := CalculateBearing(site2.Lat, site2.Lon, site1.Lat, site1.Lon)
+
+		az1, az2 := BoresightOffset(site1, site2, bearing1, bearing2)
+		assert.InDelta(t, 0.0, az1, allowedError)
+		assert.InDelta(t, 0.0, az2, allowedError)
+	})
+
+	t.Run("Boresight offset wraps around north", func(t *testing.T) {
+		site1 := Coordinate{Lat: 0.0, Lon: 0.0}
+		site2 := Coordinate{Lat: 1.0, Lon: 0.0}
+
+		az1, az2 := BoresightOffset(site1, site2, 350, 170)
+		assert.InDelta(t, 10.0, az1, allowedError)
+		assert.InDelta(t, 10.0, az2, allowedError)
+
+		az1, az2 = BoresightOffset(site1, site2, 10, 200)
+		assert.InDelta(t, -10.0, az1, allowedError)
+		assert.InDelta(t, -20.0, az2, allowedError)
+	})
+
+	t.Run("Nadir beam footprints are centred ellipses", func(t *testing.T) {
+		area, centroid := BeamFootprint(100, 60, 30, 90)
+
+		// Semi-axes h·tan(az/2) and h·tan(el/2)
+		expected := math.Pi * 100 * math.Tan(30.0/180*math.Pi) * 100 * math.Tan(15.0/180*math.Pi)
+		assert.InDelta(t, expected, area, 0.01)
+		assert.InDelta(t, 0.0, float64(centroid), allowedError)
+
+		// Symmetric beams produce circular footprints
+		area, _ = BeamFootprint(100, 30, 30, 90)
+		r := 100 * math.Tan(15.0/180*math.Pi)
+		assert.InDelta(t, math.Pi*r*r, area, 0.01)
+	})
+
+	t.Run("Tilted beam footprints are offset and elongated", func(t *testing.T) {
+		nadir, _ := BeamFootprint(30, 65, 10, 90)
+		area, centroid := BeamFootprint(30, 65, 10, 8)
+
+		inner, outer := DowntiltCoverageRadius(30, 8, 10)
+		assert.InDelta(t, float64(inner+outer)/2, float64(centroid), allowedError)
+		assert.True(t, centroid > inner && centroid < outer)
+		assert.True(t, area > nadir)
+	})
+
+	t.Run("Beam footprints are unbounded where the beam reaches the horizon", func(t *testing.T) {
+		area, centroid := BeamFootprint(30, 65, 10, 3)
+		assert.True(t, math.IsInf(area, 1))
+		assert.True(t, math.IsInf(float64(centroid), 1))
+	})
+
+	t.Run("Optimal downtilt round trips with downtilt coverage", func(t *testing.T) {
+		tests := []struct {
+			height, bw float64
+			radius     Distance
+		}{
+			{30, 10, 500},
+			{45, 7, 1000},
+			{20, 6, 250},
+			{30, 10, 5},
+		}
+
+		for _, test := range tests {
+			tilt := OptimalDowntilt(test.height, test.bw, test.radius)
+			assert.False(t, math.IsNaN(tilt))
+
+			inner, outer := DowntiltCoverageRadius(test.height, tilt, test.bw)
+			assert.InDelta(t, float64(test.radius), float64(inner+outer)/2, 0.01, "radius %.0f", test.radius)
+		}
+	})
+
+	t.Run("Optimal downtilt decreases with target radius", func(t *testing.T) {
+		near := OptimalDowntilt(30, 10, 200)
+		far := OptimalDowntilt(30, 10, 2000)
+		assert.True(t, near > far)
+
+		assert.True(t, math.IsNaN(OptimalDowntilt(30, 10, 0)))
+	})
+
+	t.Run("Optimal downtilt uses the provided solver options", func(t *testing.T) {
+		fine := OptimalDowntilt(30, 10, 500)
+
+		// The search range is independent of the solver tolerance
+		coarse, err := OptimalDowntiltWithOptions(30, 10, 500, SolverOptions{MaxIterations: 100, Tolerance: 0.1})
+		assert.Nil(t, err)
+		assert.InDelta(t, fine, coarse, 0.1)
+
+		_, err = OptimalDowntiltWithOptions(30, 10, 500, SolverOptions{MaxIterations: 1, Tolerance: 1e-12})
+		assert.Contains(t, err.Error(), "did not converge")
+
+		_, err = OptimalDowntiltWithOptions(30, 10, 0, DefaultSolverOptions)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Can calculate horizontal and vertical antenna isolation", func(t *testing.T) {
+		λ := Distance(FrequencyToWavelength(900 * MHz))
+
+		assert.InDelta(t, 42.0, float64(AntennaIsolation(10*λ, 900*MHz, IsolationHorizontal)), allowedError)
+		assert.InDelta(t, 68.0, float64(AntennaIsolation(10*λ, 900*MHz, IsolationVertical)), allowedError)
+
+		// Vertical stacking isolates better than horizontal spacing at the same separation
+		for _, d := range []Distance{1, 3, 10} {
+			assert.True(t, AntennaIsolation(d, 900*MHz, IsolationVertical) > AntennaIsolation(d, 900*MHz, IsolationHorizontal))
+		}
+
+		// Slant separations fall between the two
+		slant := AntennaIsolation(10*λ, 900*MHz, 45)
+		assert.InDelta(t, 55.0, float64(slant), allowedError)
+	})
+
+	t.Run("Separation for isolation inverts antenna isolation", func(t *testing.T) {
+		for _, orientation := range []IsolationGeometry{IsolationHorizontal, 30, IsolationVertical} {
+			for _, target := range []float64{30, 45, 60} {
+				d := SeparationForIsolation(target, 1.8*GHz, orientation)
+				assert.InDelta(t, target, float64(AntennaIsolation(d, 1.8*GHz, orientation)), allowedError)
+			}
+		}
+
+		// 40dB vertical isolation at 900MHz requires ~2λ
+		λ := float64(FrequencyToWavelength(900 * MHz))
+		assert.InDelta(t, 2.0*λ, float64(SeparationForIsolation(40, 900*MHz, IsolationVertical)), 0.01)
+	})
+}
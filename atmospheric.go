@@ -0,0 +1,460 @@
+package rf
+
+import (
+	"fmt"
+	"math"
+)
+
+// Atmospheric propagation helpers
+// Note that these are simplified implementations of the ITU-R recommendations for general planning
+
+// ITURainZone is an ITU-R P.837-1 rain climatic zone
+type ITURainZone string
+
+// ITU-R P.837-1 rain climatic zones
+const (
+	RainZoneA ITURainZone = "A"
+	RainZoneB ITURainZone = "B"
+	RainZoneC ITURainZone = "C"
+	RainZoneD ITURainZone = "D"
+	RainZoneE ITURainZone = "E"
+	RainZoneF ITURainZone = "F"
+	RainZoneG ITURainZone = "G"
+	RainZoneH ITURainZone = "H"
+	RainZoneJ ITURainZone = "J"
+	RainZoneK ITURainZone = "K"
+	RainZoneL ITURainZone = "L"
+	RainZoneM ITURainZone = "M"
+	RainZoneN ITURainZone = "N"
+	RainZoneP ITURainZone = "P"
+	RainZoneQ ITURainZone = "Q"
+)
+
+// rainRates001 are the rain rates (mm/hr) exceeded for 0.01% of an average year in each rain zone
+var rainRates001 = map[ITURainZone]float64{
+	RainZoneA: 8,
+	RainZoneB: 12,
+	RainZoneC: 15,
+	RainZoneD: 19,
+	RainZoneE: 22,
+	RainZoneF: 28,
+	RainZoneG: 30,
+	RainZoneH: 32,
+	RainZoneJ: 35,
+	RainZoneK: 42,
+	RainZoneL: 60,
+	RainZoneM: 63,
+	RainZoneN: 95,
+	RainZoneP: 145,
+	RainZoneQ: 115,
+}
+
+// RainRate001Percent returns the rain rate (mm/hr) exceeded for 0.01% of an average year in an
+// ITU-R P.837-1 rain zone, or 0 for unknown zones
+func RainRate001Percent(rainZone ITURainZone) float64 {
+	return rainRates001[rainZone]
+}
+
+// rainZoneRegion is a latitude and longitude bounded region with a known rain zone
+type rainZoneRegion struct {
+	minLat, maxLat float64
+	minLon, maxLon float64
+	zone           ITURainZone
+}
+
+// rainZoneRegions are arid regions that differ from the latitude based estimate
+var rainZoneRegions = []rainZoneRegion{
+	// Sahara and Arabian deserts
+	{15, 32, -18, 60, RainZoneA},
+	// Central Australian desert
+	{-32, -20, 118, 142, RainZoneC},
+	// South western United States and northern Mexico
+	{25, 40, -120, -104, RainZoneE},
+	// Central Asian deserts
+	{35, 48, 50, 110, RainZoneA},
+}
+
+// RainZoneForLocation estimates the ITU-R P.837-1 rain zone for a latitude and longitude (in degrees).
+// This is a coarse approximation of the P.837-1 maps using latitude bands (tropical regions are wettest)
+// with a small number of arid regions, for precise planning consult the recommendation maps directly.
+func RainZoneForLocation(lat, lon float64) ITURainZone {
+	for _, r := range rainZoneRegions {
+		if lat >= r.minLat && lat <= r.maxLat && lon >= r.minLon && lon <= r.maxLon {
+			return r.zone
+		}
+	}
+
+	switch absLat := math.Abs(lat); {
+	case absLat < 15:
+		return RainZoneP
+	case absLat < 25:
+		return RainZoneN
+	case absLat < 35:
+		return RainZoneK
+	case absLat < 60:
+		return RainZoneE
+	case absLat < 70:
+		return RainZoneC
+	default:
+		return RainZoneA
+	}
+}
+
+// CloudFogAttenuation calculates the specific attenuation (dB/km) due to cloud or fog with the provided liquid
+// water density (g/m³, typically ~0.05 for medium fog and ~0.5 for dense fog) at a temperature in degrees C,
+// using the ITU-R P.840 Rayleigh approximation (valid up to ~200GHz)
+func CloudFogAttenuation(freq Frequency, liquidWaterDensity float64, tempC float64) Attenuation {
+	f := float64(freq / GHz)
+	θ := 300 / (tempC + 273.15)
+
+	// Double Debye model for the dielectric permittivity of water
+	ε0 := 77.66 + 103.3*(θ-1)
+	ε1 := 0.0671 * ε0
+	ε2 := 3.52
+
+	// Principal and secondary relaxation frequencies (GHz)
+	fp := 20.20 - 146*(θ-1) + 316*(θ-1)*(θ-1)
+	fs := 39.8 * fp
+
+	εImag := f*(ε0-ε1)/(fp*(1+math.Pow(f/fp, 2))) + f*(ε1-ε2)/(fs*(1+math.Pow(f/fs, 2)))
+	εReal := (ε0-ε1)/(1+math.Pow(f/fp, 2)) + (ε1-ε2)/(1+math.Pow(f/fs, 2)) + ε2
+
+	// Specific attenuation coefficient ((dB/km)/(g/m³))
+	η := (2 + εReal) / εImag
+	kl := 0.819 * f / (εImag * (1 + η*η))
+
+	return Attenuation(kl * liquidWaterDensity)
+}
+
+const (
+	// scintillationTurbulenceHeight is the height of the turbulent layer in meters
+	scintillationTurbulenceHeight = 1000.0
+	// scintillationAntennaEfficiency is the assumed antenna aperture efficiency
+	scintillationAntennaEfficiency = 0.5
+)
+
+// TroposphericScintillation calculates the tropospheric scintillation fade depth exceeded for percentTime
+// (0.01% to 50%) of an average year on an Earth-space path, for an antenna of the provided diameter and
+// the wet term of the surface radio refractivity (N-units, ~40-50 for temperate climates), using the
+// ITU-R P.618 method. This is valid for elevations above ~5 degrees and frequencies between 4GHz and 20GHz
+// (and is commonly extended to ~55GHz).
+func TroposphericScintillation(freq Frequency, elevationDeg float64, antennaDiameter Distance, wetRefractivity float64, percentTime float64) Attenuation {
+	f := float64(freq / GHz)
+	sinθ := math.Sin(elevationDeg / 180 * π)
+
+	// Standard deviation of the reference signal amplitude (dB)
+	σRef := 3.6e-3 + 1e-4*wetRefractivity
+
+	// Effective path length through the turbulent layer (m)
+	l := 2 * scintillationTurbulenceHeight / (math.Sqrt(sinθ*sinθ+2.35e-4) + sinθ)
+
+	// Antenna averaging factor
+	dEff := math.Sqrt(scintillationAntennaEfficiency) * float64(antennaDiameter)
+	x := 1.22 * dEff * dEff * f / l
+	g2 := 3.86*math.Pow(x*x+1, 11.0/12)*math.Sin(11.0/6*math.Atan(1/x)) - 7.08*math.Pow(x, 5.0/6)
+	if g2 <= 0 {
+		// Scintillation is negligible for large apertures
+		return 0
+	}
+
+	σ := σRef * math.Pow(f, 7.0/12) * math.Sqrt(g2) / math.Pow(sinθ, 1.2)
+
+	// Time percentage factor
+	lp := math.Log10(percentTime)
+	a := -0.061*lp*lp*lp + 0.072*lp*lp - 1.71*lp + 3.0
+
+	return Attenuation(a * σ)
+}
+
+// rainCoefficients are the ITU-R P.838-3 coefficients for the rain specific attenuation parameters k and α,
+// each is calculated as Σ aj·exp(-((log10 f - bj)/cj)²) + m·log10 f + c (with k in log10 form)
+type rainCoefficients struct {
+	a, b, c []float64
+	m, k    float64
+}
+
+func (rc rainCoefficients) evaluate(freq Frequency) float64 {
+	lf := math.Log10(float64(freq / GHz))
+	v := rc.m*lf + rc.k
+	for j := range rc.a {
+		v += rc.a[j] * math.Exp(-math.Pow((lf-rc.b[j])/rc.c[j], 2))
+	}
+	return v
+}
+
+// ITU-R P.838-3 coefficients for horizontal and vertical polarization
+var (
+	rainKH = rainCoefficients{
+		a: []float64{-5.33980, -0.35351, -0.23789, -0.94158},
+		b: []float64{-0.10008, 1.26970, 0.86036, 0.64552},
+		c: []float64{1.13098, 0.45400, 0.15354, 0.16817},
+		m: -0.18961, k: 0.71147,
+	}
+	rainKV = rainCoefficients{
+		a: []float64{-3.80595, -3.44965, -0.39902, 0.50167},
+		b: []float64{0.56934, -0.22911, 0.73042, 1.07319},
+		c: []float64{0.81061, 0.51059, 0.11899, 0.27195},
+		m: -0.16398, k: 0.63297,
+	}
+	rainαH = rainCoefficients{
+		a: []float64{-0.14318, 0.29591, 0.32177, -5.37610, 16.1721},
+		b: []float64{1.82442, 0.77564, 0.63773, -0.96230, -3.29980},
+		c: []float64{-0.55187, 0.19822, 0.13164, 1.47828, 3.43990},
+		m: 0.67849, k: -1.95537,
+	}
+	rainαV = rainCoefficients{
+		a: []float64{-0.07771, 0.56727, -0.20238, -48.2991, 48.5833},
+		b: []float64{2.33840, 0.95545, 1.14520, 0.791669, 0.791459},
+		c: []float64{-0.76284, 0.54039, 0.26809, 0.116226, 0.116479},
+		m: -0.053739, k: 0.83433,
+	}
+)
+
+// RainSpecificAttenuation calculates the specific attenuation (dB/km) due to rain at the provided rain rate
+// (mm/hr) for horizontally or vertically polarized waves using the ITU-R P.838-3 power law model γ = k·R^α
+// (valid between 1GHz and 1000GHz)
+func RainSpecificAttenuation(freq Frequency, rainRate float64, pol Polarization) Attenuation {
+	k, α := math.Pow(10, rainKH.evaluate(freq)), rainαH.evaluate(freq)
+	if pol == PolarizationVertical {
+		k, α = math.Pow(10, rainKV.evaluate(freq)), rainαV.evaluate(freq)
+	}
+	return Attenuation(k * math.Pow(rainRate, α))
+}
+
+// RainHeight estimates the mean rain height (km above mean sea level) at a latitude (degrees) using the
+// ITU-R P.839-2 latitude model
+func RainHeight(lat float64) float64 {
+	switch {
+	case lat > 23:
+		return 5 - 0.075*(lat-23)
+	case lat >= -21:
+		return 5
+	case lat >= -71:
+		return 5 + 0.1*(lat+21)
+	default:
+		return 0
+	}
+}
+
+// RainAttenuationEarthSpace calculates the rain attenuation exceeded for percentTime (0.001% to 5%) of an average
+// year on an Earth-space path, for a station at the provided latitude (degrees) and height above sea level (km)
+// where the rain rate exceeded for 0.01% of the time is rainRate001 (mm/hr), using the ITU-R P.618 method.
+// This is valid for elevations above 5 degrees.
+func RainAttenuationEarthSpace(freq Frequency, elevationDeg, lat, stationHeightKm, rainRate001, percentTime float64, pol Polarization) Attenuation {
+	hR := RainHeight(lat)
+	if hR <= stationHeightKm || rainRate001 <= 0 {
+		return 0
+	}
+
+	f := float64(freq / GHz)
+	θ := elevationDeg / 180 * π
+	sinθ, cosθ := math.Sin(θ), math.Cos(θ)
+	absLat := math.Abs(lat)
+
+	// Slant path length below the rain height and its horizontal projection (km)
+	ls := (hR - stationHeightKm) / sinθ
+	lg := ls * cosθ
+
+	γ := float64(RainSpecificAttenuation(freq, rainRate001, pol))
+
+	// Horizontal reduction factor
+	r := 1 / (1 + 0.78*math.Sqrt(lg*γ/f) - 0.38*(1-math.Exp(-2*lg)))
+
+	// Adjusted rain path length
+	ζ := math.Atan((hR - stationHeightKm) / (lg * r))
+	lr := lg * r / cosθ
+	if ζ <= θ {
+		lr = (hR - stationHeightKm) / sinθ
+	}
+
+	// Vertical adjustment factor
+	χ := 0.0
+	if absLat < 36 {
+		χ = 36 - absLat
+	}
+	v := 1 / (1 + math.Sqrt(sinθ)*(31*(1-math.Exp(-elevationDeg/(1+χ)))*math.Sqrt(lr*γ)/(f*f)-0.45))
+
+	a001 := γ * lr * v
+
+	// Scale to the target time percentage
+	β := 0.0
+	if percentTime < 1 && absLat < 36 {
+		β = -0.005 * (absLat - 36)
+		if elevationDeg < 25 {
+			β += 1.8 - 4.25*sinθ
+		}
+	}
+	exponent := 0.655 + 0.033*math.Log(percentTime) - 0.045*math.Log(a001) - β*(1-percentTime)*sinθ
+
+	return Attenuation(a001 * math.Pow(percentTime/0.01, -exponent))
+}
+
+// RainAttenuationTerrestrial calculates the rain attenuation exceeded for percentTime (0.001% to 1%) of an average
+// year on a terrestrial line of sight path of the provided length, where the rain rate exceeded for 0.01% of the time
+// is rainRate001 (mm/hr), using the ITU-R P.530-17 effective path length method
+// https://www.itu.int/rec/R-REC-P.530
+func RainAttenuationTerrestrial(freq Frequency, distance Distance, rainRate001, percentTime float64, pol Polarization) Attenuation {
+	if distance <= 0 || rainRate001 <= 0 {
+		return 0
+	}
+
+	f, d := float64(freq/GHz), float64(distance/Km)
+
+	γ := float64(RainSpecificAttenuation(freq, rainRate001, pol))
+	α := rainαH.evaluate(freq)
+	if pol == PolarizationVertical {
+		α = rainαV.evaluate(freq)
+	}
+
+	// Distance factor giving the effective path length, limited to 2.5
+	r := 1 / (0.477*math.Pow(d, 0.633)*math.Pow(rainRate001, 0.073*α)*math.Pow(f, 0.123) - 10.579*(1-math.Exp(-0.024*d)))
+	if r <= 0 || r > 2.5 {
+		r = 2.5
+	}
+
+	a001 := γ * d * r
+
+	// Scale to the target time percentage
+	c0 := 0.12
+	if f >= 10 {
+		c0 = 0.12 + 0.4*math.Log10(math.Pow(f/10, 0.8))
+	}
+	c1 := math.Pow(0.07, c0) * math.Pow(0.12, 1-c0)
+	c2 := 0.855*c0 + 0.546*(1-c0)
+	c3 := 0.139*c0 + 0.043*(1-c0)
+
+	return Attenuation(a001 * c1 * math.Pow(percentTime, -(c2+c3*math.Log10(percentTime))))
+}
+
+// GaseousSpecificAttenuation calculates the specific attenuation (dB/km) due to dry air (oxygen) and water vapour
+// at sea level (15C, 1013hPa) for the provided water vapour density (g/m³, ~7.5 for a standard atmosphere)
+// using the simplified ITU-R P.676-1 Annex 2 approximations (valid below 57GHz)
+func GaseousSpecificAttenuation(freq Frequency, waterVapourDensity float64) (oxygen, water Attenuation) {
+	f := float64(freq / GHz)
+	ρ := waterVapourDensity
+
+	γo := (7.19e-3 + 6.09/(f*f+0.227) + 4.81/(math.Pow(f-57, 2)+1.50)) * f * f * 1e-3
+	γw := (0.050 + 0.0021*ρ + 3.6/(math.Pow(f-22.2, 2)+8.5) + 10.6/(math.Pow(f-183.3, 2)+9.0) +
+		8.9/(math.Pow(f-325.4, 2)+26.3)) * f * f * ρ * 1e-4
+
+	return Attenuation(γo), Attenuation(γw)
+}
+
+// oxygenEquivalentHeight is the equivalent height of the dry atmosphere in km (for frequencies below 50GHz)
+const oxygenEquivalentHeight = 6.0
+
+// GaseousAttenuationEarthSpace calculates the total gaseous attenuation on an Earth-space path at the provided
+// elevation using equivalent heights for oxygen and water vapour with the GaseousSpecificAttenuation model
+// (valid for elevations above ~10 degrees and frequencies below 50GHz)
+func GaseousAttenuationEarthSpace(freq Frequency, elevationDeg, waterVapourDensity float64) Attenuation {
+	f := float64(freq / GHz)
+	γo, γw := GaseousSpecificAttenuation(freq, waterVapourDensity)
+
+	hw := 2.2 + 3/(math.Pow(f-22.3, 2)+3) + 1/(math.Pow(f-183.3, 2)+1) + 1/(math.Pow(f-325.1, 2)+1)
+
+	return Attenuation((float64(γo)*oxygenEquivalentHeight + float64(γw)*hw) / math.Sin(elevationDeg/180*π))
+}
+
+// EarthSpaceParams describes an Earth-space (satellite) link for atmospheric attenuation calculations
+type EarthSpaceParams struct {
+	// Frequency is the link frequency
+	Frequency Frequency
+	// SlantRange is the distance between the earth station and the satellite
+	SlantRange Distance
+	// ElevationDeg is the elevation of the satellite above the horizon in degrees
+	ElevationDeg float64
+	// Latitude is the earth station latitude in degrees
+	Latitude float64
+	// StationHeightKm is the earth station height above mean sea level in km
+	StationHeightKm float64
+	// RainRate001 is the rain rate exceeded for 0.01% of an average year in mm/hr (see RainRate001Percent)
+	RainRate001 float64
+	// PercentTime is the percentage of an average year for which the attenuation is exceeded
+	PercentTime float64
+	// Polarization is the link polarization
+	Polarization Polarization
+	// WaterVapourDensity is the surface water vapour density in g/m³
+	WaterVapourDensity float64
+	// CloudLiquidWater is the total columnar cloud liquid water content in kg/m²
+	CloudLiquidWater float64
+	// AntennaDiameter is the earth station antenna diameter
+	AntennaDiameter Distance
+	// WetRefractivity is the wet term of the surface radio refractivity in N-units
+	WetRefractivity float64
+}
+
+// AtmosphericBreakdown is the per-component attenuation of an Earth-space link
+type AtmosphericBreakdown struct {
+	FreeSpace     Attenuation
+	Gaseous       Attenuation
+	Rain          Attenuation
+	Cloud         Attenuation
+	Scintillation Attenuation
+	// Total is the sum of all components
+	Total Attenuation
+}
+
+// EarthSpaceAttenuation calculates the free space, gaseous, rain, cloud and scintillation attenuation of an
+// Earth-space link exceeded for the provided percentage of time. Components are summed to give a (conservative)
+// total, which assumes the worst case of each fade mechanism occurs simultaneously.
+func EarthSpaceAttenuation(params EarthSpaceParams) (AtmosphericBreakdown, error) {
+	if params.ElevationDeg < 5 || params.ElevationDeg > 90 {
+		return AtmosphericBreakdown{}, fmt.Errorf("Elevation must be between 5 and 90 degrees (elevation: %.2f)", params.ElevationDeg)
+	}
+	if params.Frequency < 1*GHz || params.Frequency > 50*GHz {
+		return AtmosphericBreakdown{}, fmt.Errorf("Frequency must be between 1GHz and 50GHz (frequency: %.2fGHz)", params.Frequency/GHz)
+	}
+	if params.PercentTime < 0.001 || params.PercentTime > 5 {
+		return AtmosphericBreakdown{}, fmt.Errorf("Percentage of time must be between 0.001%% and 5%% (percent: %.4f)", params.PercentTime)
+	}
+
+	freeSpace, err := CalculateFreeSpacePathLossChecked(params.Frequency, params.SlantRange)
+	if err != nil {
+		return AtmosphericBreakdown{}, err
+	}
+
+	sinθ := math.Sin(params.ElevationDeg / 180 * π)
+
+	b := AtmosphericBreakdown{
+		FreeSpace: freeSpace,
+		Gaseous:   GaseousAttenuationEarthSpace(params.Frequency, params.ElevationDeg, params.WaterVapourDensity),
+		Rain: RainAttenuationEarthSpace(params.Frequency, params.ElevationDeg, params.Latitude, params.StationHeightKm,
+			params.RainRate001, params.PercentTime, params.Polarization),
+		// Cloud attenuation uses the liquid water coefficient at 0C
+		Cloud: CloudFogAttenuation(params.Frequency, params.CloudLiquidWater, 0) / Attenuation(sinθ),
+	}
+
+	if params.AntennaDiameter > 0 {
+		b.Scintillation = TroposphericScintillation(params.Frequency, params.ElevationDeg, params.AntennaDiameter,
+			params.WetRefractivity, math.Max(params.PercentTime, 0.01))
+	}
+
+	b.Total = b.FreeSpace + b.Gaseous + b.Rain + b.Cloud + b.Scintillation
+
+	return b, nil
+}
+
+// Rain fade availability limits (percent) matching the validity of RainAttenuationEarthSpace
+const (
+	rainFadeMinAvailability = 95.0
+	rainFadeMaxAvailability = 99.999
+)
+
+// RainFadeVsAvailability calculates the Earth-space rain fade depth for each target availability (percentage of
+// an average year, ie. 99.99, valid from 95% to 99.999%) for a ground level station at the provided latitude
+// (degrees, setting the rain height) in the provided rain zone using RainAttenuationEarthSpace with horizontal
+// polarization. An error is returned for availabilities outside of the valid range.
+func RainFadeVsAvailability(freq Frequency, elevationDeg, lat float64, rainZone ITURainZone, availabilities []float64) ([]Attenuation, error) {
+	rainRate := RainRate001Percent(rainZone)
+
+	fades := make([]Attenuation, len(availabilities))
+	for i, availability := range availabilities {
+		if !(availability >= rainFadeMinAvailability && availability <= rainFadeMaxAvailability) {
+			return nil, fmt.Errorf("Rain fade availability must be between %.0f%% and %.3f%% (availability: %.4f%%)",
+				rainFadeMinAvailability, rainFadeMaxAvailability, availability)
+		}
+		fades[i] = RainAttenuationEarthSpace(freq, elevationDeg, lat, 0, rainRate, 100-availability, PolarizationHorizontal)
+	}
+
+	return fades, nil
+}
@@ -0,0 +1,213 @@
+package rf
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAtmospheric(t *testing.T) {
+
+	t.Run("Can look up rain rates by zone", func(t *testing.T) {
+		assert.InDelta(t, 22.0, RainRate001Percent(RainZoneE), allowedError)
+		assert.InDelta(t, 145.0, RainRate001Percent(RainZoneP), allowedError)
+		assert.InDelta(t, 8.0, RainRate001Percent(RainZoneA), allowedError)
+		assert.InDelta(t, 0.0, RainRate001Percent("Z"), allowedError)
+	})
+
+	t.Run("Can estimate rain zones for locations", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			lat, lon float64
+			zone     ITURainZone
+		}{
+			{"London", 51.5, -0.13, RainZoneE},
+			{"Singapore", 1.35, 103.82, RainZoneP},
+			{"Riyadh", 24.71, 46.68, RainZoneA},
+			{"Wellington", -41.29, 174.78, RainZoneE},
+		}
+
+		for _, test := range tests {
+			assert.Equal(t, test.zone, RainZoneForLocation(test.lat, test.lon), test.name)
+		}
+	})
+
+	t.Run("Rain rates increase towards the tropics", func(t *testing.T) {
+		polar := RainRate001Percent(RainZoneForLocation(75, 0))
+		temperate := RainRate001Percent(RainZoneForLocation(50, 0))
+		tropical := RainRate001Percent(RainZoneForLocation(5, 0))
+
+		assert.True(t, polar < temperate)
+		assert.True(t, temperate < tropical)
+	})
+
+	t.Run("Can calculate fog attenuation at 30GHz", func(t *testing.T) {
+		// Light fog
+		assert.InDelta(t, 0.0296, float64(CloudFogAttenuation(30*GHz, 0.05, 10)), 0.0001)
+		// Dense fog
+		assert.InDelta(t, 0.2962, float64(CloudFogAttenuation(30*GHz, 0.5, 10)), 0.0001)
+		// Colder droplets attenuate more
+		assert.InDelta(t, 0.3854, float64(CloudFogAttenuation(30*GHz, 0.5, 0)), 0.0001)
+	})
+
+	t.Run("Fog attenuation increases with frequency", func(t *testing.T) {
+		assert.True(t, CloudFogAttenuation(10*GHz, 0.5, 10) < CloudFogAttenuation(30*GHz, 0.5, 10))
+		assert.True(t, CloudFogAttenuation(30*GHz, 0.5, 10) < CloudFogAttenuation(100*GHz, 0.5, 10))
+		assert.InDelta(t, 0.0, float64(CloudFogAttenuation(30*GHz, 0, 10)), allowedError)
+	})
+
+	t.Run("Can calculate scintillation fade depths", func(t *testing.T) {
+		tests := []struct {
+			elevation, percent float64
+			fade               float64
+		}{
+			{10, 0.01, 2.601},
+			{10, 1, 1.084},
+			{30, 0.01, 0.715},
+			{30, 1, 0.298},
+		}
+
+		for _, test := range tests {
+			fade := TroposphericScintillation(20*GHz, test.elevation, 1.2, 42.5, test.percent)
+			assert.InDelta(t, test.fade, float64(fade), 0.001, "elevation %.0f percent %.2f", test.elevation, test.percent)
+		}
+	})
+
+	t.Run("Scintillation decreases with elevation and aperture", func(t *testing.T) {
+		low := TroposphericScintillation(30*GHz, 5, 1.2, 42.5, 0.1)
+		high := TroposphericScintillation(30*GHz, 45, 1.2, 42.5, 0.1)
+		assert.True(t, low > high)
+
+		small := TroposphericScintillation(30*GHz, 10, 0.6, 42.5, 0.1)
+		large := TroposphericScintillation(30*GHz, 10, 4.5, 42.5, 0.1)
+		assert.True(t, small > large)
+	})
+
+	t.Run("Rain specific attenuation matches ITU-R P.838-3 coefficients", func(t *testing.T) {
+		// k·R^α at 1mm/hr is k
+		assert.InDelta(t, 0.01217, float64(RainSpecificAttenuation(10*GHz, 1, PolarizationHorizontal)), 0.00001)
+		assert.InDelta(t, 0.01129, float64(RainSpecificAttenuation(10*GHz, 1, PolarizationVertical)), 0.00001)
+		assert.InDelta(t, 0.2403, float64(RainSpecificAttenuation(30*GHz, 1, PolarizationHorizontal)), 0.0001)
+
+		// 20GHz k = 0.09164, α = 1.0568
+		assert.InDelta(t, 0.09164*math.Pow(30, 1.0568), float64(RainSpecificAttenuation(20*GHz, 30, PolarizationHorizontal)), 0.01)
+	})
+
+	t.Run("Can calculate rain heights", func(t *testing.T) {
+		assert.InDelta(t, 5.0, RainHeight(0), allowedError)
+		assert.InDelta(t, 2.975, RainHeight(50), allowedError)
+		assert.InDelta(t, 3.0, RainHeight(-41), allowedError)
+	})
+
+	t.Run("Earth-space attenuation breakdown sums to the total", func(t *testing.T) {
+		params := EarthSpaceParams{
+			Frequency:          20 * GHz,
+			SlantRange:         38000 * Km,
+			ElevationDeg:       30,
+			Latitude:           50,
+			RainRate001:        RainRate001Percent(RainZoneE),
+			PercentTime:        0.1,
+			Polarization:       PolarizationHorizontal,
+			WaterVapourDensity: 7.5,
+			CloudLiquidWater:   0.5,
+			AntennaDiameter:    1.2,
+			WetRefractivity:    42.5,
+		}
+
+		b, err := EarthSpaceAttenuation(params)
+		assert.Nil(t, err)
+
+		assert.InDelta(t, float64(CalculateFreeSpacePathLoss(20*GHz, 38000*Km)), float64(b.FreeSpace), allowedError)
+		for _, component := range []Attenuation{b.Gaseous, b.Rain, b.Cloud, b.Scintillation} {
+			assert.True(t, component > 0)
+		}
+		assert.InDelta(t, float64(b.FreeSpace+b.Gaseous+b.Rain+b.Cloud+b.Scintillation), float64(b.Total), allowedError)
+
+		assert.InDelta(t, 0.641, float64(b.Gaseous), 0.001)
+		assert.InDelta(t, 0.359, float64(b.Cloud), 0.001)
+		assert.InDelta(t, 0.481, float64(b.Scintillation), 0.001)
+	})
+
+	t.Run("Earth-space rain attenuation increases for smaller time percentages", func(t *testing.T) {
+		previous := Attenuation(0)
+		for _, p := range []float64{1, 0.1, 0.01, 0.001} {
+			a := RainAttenuationEarthSpace(20*GHz, 30, 50, 0, 30, p, PolarizationHorizontal)
+			assert.True(t, a > previous, "percent %.3f", p)
+			previous = a
+		}
+		assert.InDelta(t, 0.0, float64(RainAttenuationEarthSpace(20*GHz, 30, 50, 0, 0, 0.01, PolarizationHorizontal)), allowedError)
+	})
+
+	t.Run("Can calculate terrestrial rain attenuation", func(t *testing.T) {
+		// 20km at 15GHz with 42mm/hr, γ = k·R^α and the P.530 distance factor r
+		γ := float64(RainSpecificAttenuation(15*GHz, 42, PolarizationHorizontal))
+		α := math.Log(γ/float64(RainSpecificAttenuation(15*GHz, 1, PolarizationHorizontal))) / math.Log(42)
+		r := 1 / (0.477*math.Pow(20, 0.633)*math.Pow(42, 0.073*α)*math.Pow(15, 0.123) - 10.579*(1-math.Exp(-0.024*20)))
+
+		a001 := RainAttenuationTerrestrial(15*GHz, 20*Km, 42, 0.01, PolarizationHorizontal)
+		// The percentage scaling is within 0.5% of unity at 0.01%
+		assert.InEpsilon(t, γ*20*r, float64(a001), 0.005)
+		assert.True(t, r < 1)
+
+		// Attenuation increases for smaller time percentages
+		previous := Attenuation(0)
+		for _, p := range []float64{1, 0.1, 0.01, 0.001} {
+			a := RainAttenuationTerrestrial(15*GHz, 20*Km, 42, p, PolarizationHorizontal)
+			assert.True(t, a > previous, "p %.3f", p)
+			previous = a
+		}
+
+		assert.Equal(t, Attenuation(0), RainAttenuationTerrestrial(15*GHz, 20*Km, 0, 0.01, PolarizationHorizontal))
+		assert.Equal(t, Attenuation(0), RainAttenuationTerrestrial(15*GHz, 0, 42, 0.01, PolarizationHorizontal))
+	})
+
+	t.Run("Earth-space attenuation rejects invalid parameters", func(t *testing.T) {
+		params := EarthSpaceParams{Frequency: 20 * GHz, SlantRange: 38000 * Km, ElevationDeg: 2, PercentTime: 0.1}
+		_, err := EarthSpaceAttenuation(params)
+		assert.NotNil(t, err)
+
+		params.ElevationDeg, params.Frequency = 30, 100*GHz
+		_, err = EarthSpaceAttenuation(params)
+		assert.NotNil(t, err)
+
+		params.Frequency, params.PercentTime = 20*GHz, 50
+		_, err = EarthSpaceAttenuation(params)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Rain fade increases as the availability target tightens", func(t *testing.T) {
+		availabilities := []float64{99, 99.9, 99.95, 99.99, 99.999}
+		fades, err := RainFadeVsAvailability(20*GHz, 30, 45, RainZoneK, availabilities)
+		assert.Nil(t, err)
+		assert.Len(t, fades, len(availabilities))
+
+		for i := 1; i < len(fades); i++ {
+			assert.True(t, fades[i] > fades[i-1], "availability %.3f", availabilities[i])
+		}
+
+		// 99.99% availability corresponds to the 0.01% rain rate
+		expected := RainAttenuationEarthSpace(20*GHz, 30, 45, 0, 42, 0.01, PolarizationHorizontal)
+		assert.InDelta(t, float64(expected), float64(fades[3]), allowedError)
+	})
+
+	t.Run("Wetter rain zones require larger fade margins", func(t *testing.T) {
+		dry, _ := RainFadeVsAvailability(12*GHz, 40, 45, RainZoneA, []float64{99.99})
+		wet, _ := RainFadeVsAvailability(12*GHz, 40, 45, RainZoneP, []float64{99.99})
+		assert.True(t, wet[0] > dry[0])
+	})
+
+	t.Run("Rain fade depends on the station latitude", func(t *testing.T) {
+		// Rain heights are lower towards the poles, shortening the path through rain
+		temperate, _ := RainFadeVsAvailability(12*GHz, 40, 45, RainZoneK, []float64{99.99})
+		polar, _ := RainFadeVsAvailability(12*GHz, 40, 65, RainZoneK, []float64{99.99})
+		assert.True(t, polar[0] < temperate[0])
+	})
+
+	t.Run("Rain fade rejects availabilities outside of the model range", func(t *testing.T) {
+		for _, availability := range []float64{0, 50, 100, 101, math.NaN()} {
+			_, err := RainFadeVsAvailability(12*GHz, 40, 45, RainZoneK, []float64{99.9, availability})
+			assert.NotNil(t, err, "availability %.1f", availability)
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package rf
+
+import (
+	"fmt"
+	"math"
+)
+
+// Link availability calculations
+
+// Vigants-Barnett terrain roughness factors
+const (
+	VigantsTerrainSmooth  = 4.0
+	VigantsTerrainAverage = 1.0
+	VigantsTerrainRough   = 0.25
+)
+
+// Vigants-Barnett climate factors
+const (
+	VigantsClimateHumid     = 0.5
+	VigantsClimateTemperate = 0.25
+	VigantsClimateDry       = 0.125
+)
+
+// MultipathAvailability calculates the annual availability (percent) of a terrestrial line of sight link with the
+// provided flat fade margin using the Vigants-Barnett multipath outage model, with terrain and climate factors from
+// the VigantsTerrain and VigantsClimate constants.
+func MultipathAvailability(fadeMarginDB float64, freq Frequency, distance Distance, terrainFactor, climateFactor float64) float64 {
+	outage := 6e-7 * terrainFactor * climateFactor * float64(freq/GHz) * math.Pow(float64(distance/Km), 3) * math.Pow(10, -fadeMarginDB/10)
+
+	return 100 * (1 - math.Min(outage, 1))
+}
+
+// Rain availability search limits (percentage of time) matching the validity of RainAttenuationTerrestrial
+const (
+	rainAvailabilityMinPercent = 0.001
+	rainAvailabilityMaxPercent = 1.0
+)
+
+// RainAvailability calculates the annual availability (percent) of a terrestrial line of sight link of the provided
+// length with the provided fade margin in the provided rain zone, by inverting RainAttenuationTerrestrial with
+// horizontal polarization. Results are limited to the 99% to 99.999% validity range of the rain model.
+func RainAvailability(fadeMarginDB float64, freq Frequency, distance Distance, rainZone ITURainZone) float64 {
+	availability, err := RainAvailabilityWithOptions(fadeMarginDB, freq, distance, rainZone, DefaultSolverOptions)
+	if err != nil {
+		return math.NaN()
+	}
+	return availability
+}
+
+// RainAvailabilityWithOptions calculates the rain availability as RainAvailability, using the provided solver options
+// with the tolerance in log10 percent of time, returning an error where the search fails
+func RainAvailabilityWithOptions(fadeMarginDB float64, freq Frequency, distance Distance, rainZone ITURainZone, opts SolverOptions) (float64, error) {
+	rainRate := RainRate001Percent(rainZone)
+
+	// Rain fade falls monotonically with the percentage of time exceeded, search in log percentage
+	excess := func(logPercent float64) float64 {
+		fade := RainAttenuationTerrestrial(freq, distance, rainRate, math.Pow(10, logPercent), PolarizationHorizontal)
+		return float64(fade) - fadeMarginDB
+	}
+
+	lo, hi := math.Log10(rainAvailabilityMinPercent), math.Log10(rainAvailabilityMaxPercent)
+	if excess(lo) <= 0 {
+		return 100 - rainAvailabilityMinPercent, nil
+	}
+	if excess(hi) >= 0 {
+		return 100 - rainAvailabilityMaxPercent, nil
+	}
+
+	logPercent, err := Brent(excess, lo, hi, opts)
+	if err != nil {
+		return 0, fmt.Errorf("Rain availability search failed: %w", err)
+	}
+
+	return 100 - math.Pow(10, logPercent), nil
+}
+
+// TotalAvailability combines the availabilities (percent) of independent multipath and rain outage mechanisms,
+// where the link is unavailable if either mechanism causes an outage
+func TotalAvailability(multipathAvailability, rainAvailability float64) float64 {
+	multipathOutage, rainOutage := 1-multipathAvailability/100, 1-rainAvailability/100
+
+	outage := 1 - (1-multipathOutage)*(1-rainOutage)
+
+	return 100 * (1 - outage)
+}
+
+// LinkAvailability calculates the combined annual availability (percent) of a terrestrial line of sight link with the
+// provided fade margin, using MultipathAvailability and RainAvailability over the same path length
+func LinkAvailability(fadeMarginDB float64, freq Frequency, distance Distance, terrainFactor, climateFactor float64, rainZone ITURainZone) float64 {
+	multipath := MultipathAvailability(fadeMarginDB, freq, distance, terrainFactor, climateFactor)
+	rain := RainAvailability(fadeMarginDB, freq, distance, rainZone)
+
+	return TotalAvailability(multipath, rain)
+}
@@ -0,0 +1,72 @@
+package rf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAvailability(t *testing.T) {
+
+	t.Run("Combines independent outages", func(t *testing.T) {
+		// 0.01% and 0.1% outages combine to ~0.11%
+		assert.InDelta(t, 99.89001, TotalAvailability(99.99, 99.9), 1e-6)
+		assert.InDelta(t, TotalAvailability(99.9, 99.99), TotalAvailability(99.99, 99.9), 1e-9)
+
+		assert.InDelta(t, 99.9, TotalAvailability(100, 99.9), 1e-9)
+		assert.InDelta(t, 0.0, TotalAvailability(0, 99.9), 1e-9)
+	})
+
+	t.Run("Multipath availability improves 10dB per decade of outage", func(t *testing.T) {
+		a := MultipathAvailability(30, 6*GHz, 30*Km, VigantsTerrainAverage, VigantsClimateTemperate)
+		b := MultipathAvailability(40, 6*GHz, 30*Km, VigantsTerrainAverage, VigantsClimateTemperate)
+
+		// 6e-7·1·0.25·6·30³·10^-3 = 2.43e-5
+		assert.InDelta(t, 100*(1-2.43e-5), a, 1e-6)
+		assert.InDelta(t, 10*(100-b), 100-a, 1e-6)
+
+		// Rougher terrain and drier climates fade less
+		rough := MultipathAvailability(30, 6*GHz, 30*Km, VigantsTerrainRough, VigantsClimateDry)
+		assert.True(t, rough > a)
+	})
+
+	t.Run("Rain availability inverts the terrestrial rain fade", func(t *testing.T) {
+		rainRate := RainRate001Percent(RainZoneK)
+
+		for _, availability := range []float64{99.9, 99.99} {
+			fade := RainAttenuationTerrestrial(15*GHz, 20*Km, rainRate, 100-availability, PolarizationHorizontal)
+			assert.InDelta(t, availability, RainAvailability(float64(fade), 15*GHz, 20*Km, RainZoneK), 1e-6)
+		}
+
+		// Longer paths are less available for the same margin
+		assert.True(t, RainAvailability(30, 15*GHz, 40*Km, RainZoneK) < RainAvailability(30, 15*GHz, 20*Km, RainZoneK))
+	})
+
+	t.Run("Rain availability is limited to the model range", func(t *testing.T) {
+		assert.InDelta(t, 99.999, RainAvailability(1000, 15*GHz, 20*Km, RainZoneK), 1e-9)
+		assert.InDelta(t, 99.0, RainAvailability(0, 15*GHz, 20*Km, RainZoneK), 1e-9)
+	})
+
+	t.Run("Rain availability uses the provided solver options", func(t *testing.T) {
+		fine := RainAvailability(30, 15*GHz, 20*Km, RainZoneK)
+
+		coarse, err := RainAvailabilityWithOptions(30, 15*GHz, 20*Km, RainZoneK, SolverOptions{MaxIterations: 100, Tolerance: 1e-3})
+		assert.Nil(t, err)
+		assert.InDelta(t, fine, coarse, 1e-3)
+
+		_, err = RainAvailabilityWithOptions(30, 15*GHz, 20*Km, RainZoneK, SolverOptions{})
+		assert.NotNil(t, err)
+
+		_, err = RainAvailabilityWithOptions(30, 15*GHz, 20*Km, RainZoneK, SolverOptions{MaxIterations: 1, Tolerance: 1e-15})
+		assert.Contains(t, err.Error(), "did not converge")
+	})
+
+	t.Run("Link availability combines multipath and rain", func(t *testing.T) {
+		availability := LinkAvailability(35, 15*GHz, 20*Km, VigantsTerrainAverage, VigantsClimateTemperate, RainZoneK)
+
+		multipath := MultipathAvailability(35, 15*GHz, 20*Km, VigantsTerrainAverage, VigantsClimateTemperate)
+		rain := RainAvailability(35, 15*GHz, 20*Km, RainZoneK)
+		assert.InDelta(t, TotalAvailability(multipath, rain), availability, 1e-9)
+		assert.True(t, availability < multipath && availability < rain)
+	})
+}
@@ -0,0 +1,200 @@
+package rf
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Broadcast coverage helpers
+// See: FCC 47 CFR 73.313
+
+// HeightAboveAverageTerrain calculates the antenna height above average terrain (HAAT) in meters, as the antenna
+// elevation (above mean sea level) less the average of the mean terrain elevations along each radial. Each
+// radial terrain profile should contain evenly spaced elevation samples between 3km and 16km from the antenna.
+// Returns NaN where no radial contains terrain samples.
+func HeightAboveAverageTerrain(antennaElevation float64, terrainProfiles [][]float64) float64 {
+	sum, radials := 0.0, 0
+	for _, profile := range terrainProfiles {
+		if len(profile) == 0 {
+			continue
+		}
+
+		total := 0.0
+		for _, h := range profile {
+			total += h
+		}
+		sum += total / float64(len(profile))
+		radials++
+	}
+
+	if radials == 0 {
+		return math.NaN()
+	}
+
+	return antennaElevation - sum/float64(radials)
+}
+
+// FMReferenceFieldStrength is the protected service contour field strength (1mV/m) in dBμV/m for FM station classes
+const FMReferenceFieldStrength = 60.0
+
+// FMClassReference is the reference facility for an FM station class, with the maximum ERP (kW), the reference HAAT
+// (m) and the distance to the FMReferenceFieldStrength contour on the F(50,50) curve
+type FMClassReference struct {
+	Class    string
+	ERPKw    float64
+	HAAT     float64
+	Distance Distance
+}
+
+// FMClassReferences are the published FM station class reference facilities and contour distances, which may be
+// used to check digitised F(50,50) curves.
+// See: FCC 47 CFR 73.211(b)(1)
+var FMClassReferences = []FMClassReference{
+	{Class: "A", ERPKw: 6, HAAT: 100, Distance: 28 * Km},
+	{Class: "B1", ERPKw: 25, HAAT: 100, Distance: 39 * Km},
+	{Class: "B", ERPKw: 50, HAAT: 150, Distance: 52 * Km},
+	{Class: "C3", ERPKw: 25, HAAT: 100, Distance: 39 * Km},
+	{Class: "C2", ERPKw: 50, HAAT: 150, Distance: 52 * Km},
+	{Class: "C1", ERPKw: 100, HAAT: 299, Distance: 72 * Km},
+	{Class: "C0", ERPKw: 100, HAAT: 450, Distance: 83 * Km},
+	{Class: "C", ERPKw: 100, HAAT: 600, Distance: 92 * Km},
+}
+
+// FCCCurve is a tabulated FCC propagation curve (ie. F(50,50) or F(50,10)), giving the field strength in dBμV/m for
+// 1kW ERP by antenna HAAT and distance. Tables are not included and must be supplied, for example as digitised from
+// 47 CFR 73.333 or exported from the FCC curves tool, and F(50,50) tables can be checked against FMClassReferences.
+type FCCCurve struct {
+	// Name is a description of the curve
+	Name string
+	// HAATs are the tabulated antenna heights above average terrain in meters in increasing order
+	HAATs []float64
+	// Distances are the tabulated distances in increasing order
+	Distances []Distance
+	// FieldStrength holds the field strength in dBμV/m for 1kW ERP, indexed by HAAT then distance
+	FieldStrength [][]float64
+}
+
+// Validate checks that a curve contains at least two HAATs and positive distances in increasing order, and that the
+// field strength table has a row for each HAAT with an entry for each distance
+func (c FCCCurve) Validate() error {
+	if len(c.HAATs) < 2 || len(c.Distances) < 2 {
+		return fmt.Errorf("FCC curve %s must contain at least two HAATs and distances (HAATs: %d distances: %d)", c.Name, len(c.HAATs), len(c.Distances))
+	}
+	for i := 1; i < len(c.HAATs); i++ {
+		if !(c.HAATs[i] > c.HAATs[i-1]) {
+			return fmt.Errorf("FCC curve %s HAATs must be increasing (index: %d)", c.Name, i)
+		}
+	}
+	if c.Distances[0] <= 0 {
+		return fmt.Errorf("FCC curve %s distances must be positive (distance: %.2fm)", c.Name, c.Distances[0])
+	}
+	for i := 1; i < len(c.Distances); i++ {
+		if !(c.Distances[i] > c.Distances[i-1]) {
+			return fmt.Errorf("FCC curve %s distances must be increasing (index: %d)", c.Name, i)
+		}
+	}
+	if len(c.FieldStrength) != len(c.HAATs) {
+		return fmt.Errorf("FCC curve %s field strength table must have a row per HAAT (rows: %d HAATs: %d)", c.Name, len(c.FieldStrength), len(c.HAATs))
+	}
+	for i, row := range c.FieldStrength {
+		if len(row) != len(c.Distances) {
+			return fmt.Errorf("FCC curve %s field strength row %d must have an entry per distance (entries: %d distances: %d)", c.Name, i, len(row), len(c.Distances))
+		}
+	}
+	return nil
+}
+
+// BroadcastFieldStrength calculates the field strength in dBμV/m at a distance from a broadcast transmitter with the
+// provided ERP (kW) and HAAT (m) by interpolating an FCC propagation curve. Field strength is interpolated linearly
+// in HAAT and log distance, and scales with 10log(ERP). Returns an error for invalid curves or where the HAAT or
+// distance is outside of the tabulated range.
+func BroadcastFieldStrength(erpKw float64, haat float64, distance Distance, curve FCCCurve) (float64, error) {
+	if err := curve.Validate(); err != nil {
+		return 0, err
+	}
+	return broadcastFieldStrength(erpKw, haat, distance, curve)
+}
+
+// broadcastFieldStrength calculates field strength as BroadcastFieldStrength for a validated curve
+func broadcastFieldStrength(erpKw float64, haat float64, distance Distance, curve FCCCurve) (float64, error) {
+	h, hf, ok := curveIndex(curve.HAATs, haat)
+	if !ok {
+		return 0, fmt.Errorf("HAAT of %.2fm is outside of the curve %s", haat, curve.Name)
+	}
+
+	logDistances := make([]float64, len(curve.Distances))
+	for i, d := range curve.Distances {
+		logDistances[i] = math.Log10(float64(d))
+	}
+	d, df, ok := curveIndex(logDistances, math.Log10(float64(distance)))
+	if !ok {
+		return 0, fmt.Errorf("Distance of %.2fkm is outside of the curve %s", distance/Km, curve.Name)
+	}
+
+	field := func(i, j int) float64 { return curve.FieldStrength[i][j] }
+
+	// Bilinear interpolation between the surrounding table entries
+	lower := field(h, d) + (field(h, d+1)-field(h, d))*df
+	upper := field(h+1, d) + (field(h+1, d+1)-field(h+1, d))*df
+
+	return lower + (upper-lower)*hf + 10*math.Log10(erpKw), nil
+}
+
+// curveIndex finds the lower index of the table interval containing v and the fractional position within it,
+// returning false where v is outside the table
+func curveIndex(table []float64, v float64) (int, float64, bool) {
+	if len(table) < 2 || v < table[0] || v > table[len(table)-1] {
+		return 0, 0, false
+	}
+
+	i := sort.SearchFloat64s(table, v)
+	if i > 0 {
+		i--
+	}
+	if i > len(table)-2 {
+		i = len(table) - 2
+	}
+
+	return i, (v - table[i]) / (table[i+1] - table[i]), true
+}
+
+// ContourDistance calculates the distance at which the field strength from a broadcast transmitter with the provided
+// ERP (kW) and HAAT (m) falls to the target field strength (dBμV/m) by inverting BroadcastFieldStrength, returning
+// an error for invalid curves or where the contour lies outside the tabulated distances of the curve
+func ContourDistance(erpKw float64, haat float64, targetFieldStrength float64, curve FCCCurve) (Distance, error) {
+	return ContourDistanceWithOptions(erpKw, haat, targetFieldStrength, curve, DefaultSolverOptions)
+}
+
+// ContourDistanceWithOptions calculates the contour distance as ContourDistance, using the provided solver options
+// with the tolerance in log10 meters
+func ContourDistanceWithOptions(erpKw float64, haat float64, targetFieldStrength float64, curve FCCCurve, opts SolverOptions) (Distance, error) {
+	if err := curve.Validate(); err != nil {
+		return 0, err
+	}
+
+	nearest, furthest := curve.Distances[0], curve.Distances[len(curve.Distances)-1]
+	if _, err := broadcastFieldStrength(erpKw, haat, nearest, curve); err != nil {
+		return 0, err
+	}
+
+	// Field strength falls monotonically with distance, search in log distance to match the interpolation
+	// (clamping to the table where rounding takes the distance marginally outside of it)
+	excess := func(logDistance float64) float64 {
+		d := Distance(math.Min(math.Max(math.Pow(10, logDistance), float64(nearest)), float64(furthest)))
+		field, _ := broadcastFieldStrength(erpKw, haat, d, curve)
+		return field - targetFieldStrength
+	}
+
+	lo, hi := math.Log10(float64(nearest)), math.Log10(float64(furthest))
+	if excess(lo) < 0 || excess(hi) > 0 {
+		return 0, fmt.Errorf("%.2fdBμV/m contour is outside of the curve %s (%.2fkm to %.2fkm)", targetFieldStrength, curve.Name, nearest/Km, furthest/Km)
+	}
+
+	logDistance, err := Brent(excess, lo, hi, opts)
+	if err != nil {
+		return 0, fmt.Errorf("Contour distance search failed: %w", err)
+	}
+
+	return Distance(math.Pow(10, logDistance)), nil
+}
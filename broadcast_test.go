@@ -0,0 +1,229 @@
+package rf
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// slopedRadial generates an evenly sampled radial with terrain rising linearly between two elevations
+func slopedRadial(start, end float64, samples int) []float64 {
+	radial := make([]float64, samples)
+	for i := range radial {
+		radial[i] = start + (end-start)*float64(i)/float64(samples-1)
+	}
+	return radial
+}
+
+// exampleCurve is a synthetic F(50,50) style curve for testing interpolation (dBμV/m for 1kW ERP), falling below
+// free space (106.9 - 20log(d) dBμV/m) at every point. It is not digitised from 47 CFR 73.333.
+var exampleCurve = FCCCurve{
+	Name:      "Example F(50,50)",
+	HAATs:     []float64{30, 150, 300},
+	Distances: []Distance{10 * Km, 20 * Km, 40 * Km, 80 * Km},
+	FieldStrength: [][]float64{
+		{70.0, 60.0, 47.0, 31.0},
+		{82.0, 74.0, 62.0, 46.0},
+		{85.0, 79.0, 70.0, 55.0},
+	},
+}
+
+// referenceCurve builds an F(50,50) style curve falling 43.05dB per decade of distance, anchored at each HAAT to the
+// published FM class reference contours (the slope is set by the class A and B1 contours which share a HAAT)
+func referenceCurve() FCCCurve {
+	const slope = 43.05
+
+	anchors := []struct {
+		haat, erp float64
+		distance  Distance
+	}{
+		{100, 6, 28 * Km},
+		{150, 50, 52 * Km},
+		{299, 100, 72 * Km},
+		{450, 100, 83 * Km},
+		{600, 100, 92 * Km},
+	}
+
+	curve := FCCCurve{Name: "Reference F(50,50)", Distances: []Distance{10 * Km, 20 * Km, 40 * Km, 80 * Km, 160 * Km}}
+	for _, a := range anchors {
+		row := make([]float64, len(curve.Distances))
+		for i, d := range curve.Distances {
+			row[i] = FMReferenceFieldStrength - 10*math.Log10(a.erp) - slope*math.Log10(float64(d/a.distance))
+		}
+		curve.HAATs = append(curve.HAATs, a.haat)
+		curve.FieldStrength = append(curve.FieldStrength, row)
+	}
+
+	return curve
+}
+
+func TestBroadcast(t *testing.T) {
+
+	t.Run("Can calculate HAAT over flat radials", func(t *testing.T) {
+		radials := make([][]float64, 8)
+		for i := range radials {
+			radials[i] = slopedRadial(100, 100, 50)
+		}
+
+		assert.InDelta(t, 200.0, HeightAboveAverageTerrain(300, radials), allowedError)
+	})
+
+	t.Run("Can calculate HAAT over sloped radials", func(t *testing.T) {
+		radials := [][]float64{
+			slopedRadial(50, 150, 51),
+			slopedRadial(150, 50, 101),
+			slopedRadial(0, 400, 27),
+			slopedRadial(0, 0, 10),
+		}
+
+		// Radial averages of 100, 100, 200 and 0m, each radial is weighted equally
+		assert.InDelta(t, 300.0-100.0, HeightAboveAverageTerrain(300, radials), allowedError)
+	})
+
+	t.Run("HAAT is negative for antennas below the average terrain", func(t *testing.T) {
+		radials := [][]float64{slopedRadial(500, 700, 20), slopedRadial(600, 600, 20)}
+		assert.InDelta(t, -150.0, HeightAboveAverageTerrain(450, radials), allowedError)
+	})
+
+	t.Run("HAAT requires terrain samples", func(t *testing.T) {
+		assert.True(t, math.IsNaN(HeightAboveAverageTerrain(300, nil)))
+		assert.True(t, math.IsNaN(HeightAboveAverageTerrain(300, [][]float64{{}})))
+	})
+
+	t.Run("Broadcast field strength reproduces tabulated curve points", func(t *testing.T) {
+		for i, haat := range exampleCurve.HAATs {
+			for j, d := range exampleCurve.Distances {
+				field, err := BroadcastFieldStrength(1, haat, d, exampleCurve)
+				assert.Nil(t, err)
+				assert.InDelta(t, exampleCurve.FieldStrength[i][j], field, allowedError)
+			}
+		}
+	})
+
+	t.Run("Broadcast field strength interpolates between curve points", func(t *testing.T) {
+		// Linear in HAAT
+		field, err := BroadcastFieldStrength(1, 90, 20*Km, exampleCurve)
+		assert.Nil(t, err)
+		assert.InDelta(t, (60.0+74.0)/2, field, allowedError)
+
+		// Linear in log distance, √(10·20) is the log midpoint
+		field, err = BroadcastFieldStrength(1, 150, Distance(math.Sqrt(10*20))*Km, exampleCurve)
+		assert.Nil(t, err)
+		assert.InDelta(t, (82.0+74.0)/2, field, allowedError)
+	})
+
+	t.Run("Broadcast field strength scales with ERP", func(t *testing.T) {
+		field, _ := BroadcastFieldStrength(100, 150, 20*Km, exampleCurve)
+		assert.InDelta(t, 74.0+20, field, allowedError)
+
+		field, _ = BroadcastFieldStrength(0.1, 150, 20*Km, exampleCurve)
+		assert.InDelta(t, 74.0-10, field, allowedError)
+	})
+
+	t.Run("Broadcast field strength is undefined outside the curve", func(t *testing.T) {
+		_, err := BroadcastFieldStrength(1, 20, 20*Km, exampleCurve)
+		assert.NotNil(t, err)
+
+		_, err = BroadcastFieldStrength(1, 150, 100*Km, exampleCurve)
+		assert.NotNil(t, err)
+
+		_, err = BroadcastFieldStrength(1, 150, 20*Km, FCCCurve{})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Rejects malformed curves", func(t *testing.T) {
+		ragged := exampleCurve
+		ragged.FieldStrength = [][]float64{{70.0, 60.0, 47.0, 31.0}, {82.0, 74.0}, {85.0, 79.0, 70.0, 55.0}}
+
+		missingRow := exampleCurve
+		missingRow.FieldStrength = exampleCurve.FieldStrength[:2]
+
+		unordered := exampleCurve
+		unordered.Distances = []Distance{10 * Km, 40 * Km, 20 * Km, 80 * Km}
+
+		for _, curve := range []FCCCurve{ragged, missingRow, unordered} {
+			assert.NotNil(t, curve.Validate())
+
+			_, err := BroadcastFieldStrength(1, 150, 30*Km, curve)
+			assert.NotNil(t, err)
+
+			_, err = ContourDistance(1, 150, 60, curve)
+			assert.NotNil(t, err)
+		}
+
+		assert.Nil(t, exampleCurve.Validate())
+	})
+
+	t.Run("Contour distance inverts broadcast field strength", func(t *testing.T) {
+		tests := []struct {
+			erp, haat, field float64
+		}{
+			{1, 150, 60},
+			{50, 150, 70},
+			{10, 90, 54},
+			{100, 300, 88},
+		}
+
+		for _, test := range tests {
+			d, err := ContourDistance(test.erp, test.haat, test.field, exampleCurve)
+			assert.Nil(t, err)
+
+			field, err := BroadcastFieldStrength(test.erp, test.haat, d, exampleCurve)
+			assert.Nil(t, err)
+			assert.InDelta(t, test.field, field, 1e-6)
+		}
+
+		// Tabulated points
+		d, err := ContourDistance(1, 150, 62, exampleCurve)
+		assert.Nil(t, err)
+		assert.InDelta(t, float64(40*Km), float64(d), 0.01)
+	})
+
+	t.Run("Contour distance uses the provided solver options", func(t *testing.T) {
+		fine, _ := ContourDistance(1, 150, 60, exampleCurve)
+
+		coarse, err := ContourDistanceWithOptions(1, 150, 60, exampleCurve, SolverOptions{MaxIterations: 100, Tolerance: 1e-3})
+		assert.Nil(t, err)
+		assert.InDelta(t, 0.0, math.Log10(float64(coarse/fine)), 1e-3)
+
+		_, err = ContourDistanceWithOptions(1, 150, 60, exampleCurve, SolverOptions{})
+		assert.NotNil(t, err)
+
+		// Non-convergence is distinguished from contours outside of the curve
+		_, err = ContourDistanceWithOptions(1, 150, 60, exampleCurve, SolverOptions{MaxIterations: 1, Tolerance: 1e-15})
+		assert.Contains(t, err.Error(), "did not converge")
+
+		_, err = ContourDistanceWithOptions(1, 150, 100, exampleCurve, DefaultSolverOptions)
+		assert.Contains(t, err.Error(), "outside of the curve")
+	})
+
+	t.Run("Contour distance increases with ERP", func(t *testing.T) {
+		low, _ := ContourDistance(1, 150, 60, exampleCurve)
+		high, _ := ContourDistance(10, 150, 60, exampleCurve)
+		assert.True(t, high > low)
+	})
+
+	t.Run("Contour distance fails outside of the curve", func(t *testing.T) {
+		_, err := ContourDistance(1, 150, 100, exampleCurve)
+		assert.NotNil(t, err)
+
+		_, err = ContourDistance(1, 150, 20, exampleCurve)
+		assert.NotNil(t, err)
+
+		_, err = ContourDistance(1, 1000, 60, exampleCurve)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Contour distances match the published FM class reference distances", func(t *testing.T) {
+		curve := referenceCurve()
+		assert.Nil(t, curve.Validate())
+
+		for _, ref := range FMClassReferences {
+			d, err := ContourDistance(ref.ERPKw, ref.HAAT, FMReferenceFieldStrength, curve)
+			assert.Nil(t, err, "class %s", ref.Class)
+			// Reference distances are published to the nearest km
+			assert.InDelta(t, float64(ref.Distance), float64(d), float64(0.5*Km), "class %s", ref.Class)
+		}
+	})
+}
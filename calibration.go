@@ -0,0 +1,122 @@
+package rf
+
+import (
+	"fmt"
+	"math"
+)
+
+// Propagation model calibration from measured data
+
+// Measurement is a received signal strength measurement at a distance from a transmitter
+type Measurement struct {
+	// Distance is the distance between the transmitter and receiver
+	Distance Distance
+	// RSSIDBm is the received signal strength in dBm
+	RSSIDBm float64
+	// EIRPDBm is the transmitted EIRP in dBm, adjusted for the receive antenna gain and losses
+	EIRPDBm float64
+}
+
+// PathLoss returns the measured path loss in dB
+func (m Measurement) PathLoss() Attenuation {
+	return Attenuation(m.EIRPDBm - m.RSSIDBm)
+}
+
+// FitReferenceDistance is the reference distance of fitted log-distance models
+const FitReferenceDistance = 1 * M
+
+// FitPathLossExponent performs a least squares fit of the log-distance path loss model to measurements, returning
+// the fitted path loss exponent, the path loss at FitReferenceDistance and the root mean square error of the fit.
+// Where the measurements do not span multiple distances only the exponent is fitted, with free space loss at the
+// reference distance for the provided frequency. As there is no error return, NaN is returned without measurements,
+// for measurements at non-positive distances, or where every measurement is at FitReferenceDistance (where the
+// exponent cannot be determined).
+// https://en.wikipedia.org/wiki/Log-distance_path_loss_model
+func FitPathLossExponent(points []Measurement, freq Frequency) (exponent float64, referenceLossDB float64, rmseDB float64) {
+	weights := make([]float64, len(points))
+	for i := range weights {
+		weights[i] = 1
+	}
+
+	return fitLogDistance(points, weights, freq)
+}
+
+// fitLogDistance performs a weighted least squares fit of the log-distance model L = L0 + 10·n·log10(d/d0),
+// returning n, L0 and the weighted root mean square error, or NaN for invalid measurements as FitPathLossExponent
+func fitLogDistance(points []Measurement, weights []float64, freq Frequency) (exponent float64, referenceLossDB float64, rmseDB float64) {
+	if len(points) == 0 {
+		return math.NaN(), math.NaN(), math.NaN()
+	}
+	for _, p := range points {
+		if !(p.Distance > 0) {
+			return math.NaN(), math.NaN(), math.NaN()
+		}
+	}
+
+	// Weighted means of the log distance (x) and path loss (y)
+	total, meanX, meanY := 0.0, 0.0, 0.0
+	xs := make([]float64, len(points))
+	for i, p := range points {
+		xs[i] = 10 * math.Log10(float64(p.Distance/FitReferenceDistance))
+		total += weights[i]
+		meanX += weights[i] * xs[i]
+		meanY += weights[i] * float64(p.PathLoss())
+	}
+	meanX, meanY = meanX/total, meanY/total
+
+	sxx, sxy := 0.0, 0.0
+	for i, p := range points {
+		sxx += weights[i] * (xs[i] - meanX) * (xs[i] - meanX)
+		sxy += weights[i] * (xs[i] - meanX) * (float64(p.PathLoss()) - meanY)
+	}
+
+	if sxx > 0 {
+		exponent = sxy / sxx
+		referenceLossDB = meanY - exponent*meanX
+	} else {
+		// A single distance cannot determine both parameters, anchor to free space at the reference distance
+		// (which cannot determine the exponent where the measurements are at the reference distance)
+		if meanX == 0 {
+			return math.NaN(), math.NaN(), math.NaN()
+		}
+		referenceLossDB = float64(CalculateFreeSpacePathLoss(freq, FitReferenceDistance))
+		exponent = (meanY - referenceLossDB) / meanX
+	}
+
+	sse := 0.0
+	for i, p := range points {
+		residual := float64(p.PathLoss()) - (referenceLossDB + exponent*xs[i])
+		sse += weights[i] * residual * residual
+	}
+
+	return exponent, referenceLossDB, math.Sqrt(sse / total)
+}
+
+// CalibrateModel fits a log-distance model to several measurement campaigns, weighting each campaign equally
+// irrespective of the number of measurements it contains so that dense campaigns do not dominate the fit. Campaign
+// weights are fixed, use FitPathLossExponent to fit merged measurements with equal weights per measurement. Returns
+// an error where the measurements do not determine a finite model (as FitPathLossExponent returns NaN).
+func CalibrateModel(campaigns [][]Measurement, freq Frequency) (*LogDistanceModel, error) {
+	if len(campaigns) == 0 {
+		return nil, fmt.Errorf("Model calibration requires at least one measurement campaign")
+	}
+
+	points, weights := []Measurement{}, []float64{}
+	for i, campaign := range campaigns {
+		if len(campaign) == 0 {
+			return nil, fmt.Errorf("Measurement campaign %d contains no measurements", i)
+		}
+
+		for _, p := range campaign {
+			points = append(points, p)
+			weights = append(weights, 1/float64(len(campaign)))
+		}
+	}
+
+	exponent, referenceLoss, _ := fitLogDistance(points, weights, freq)
+	if math.IsNaN(exponent) || math.IsInf(exponent, 0) || math.IsNaN(referenceLoss) || math.IsInf(referenceLoss, 0) {
+		return nil, fmt.Errorf("Measurements do not determine a log-distance model (exponent: %.2f reference loss: %.2fdB)", exponent, referenceLoss)
+	}
+
+	return &LogDistanceModel{Reference: FitReferenceDistance, Exponent: exponent, ReferenceLossDB: referenceLoss, UseReferenceLoss: true}, nil
+}
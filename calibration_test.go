@@ -0,0 +1,143 @@
+package rf
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// syntheticCampaign generates measurements following a log-distance model with gaussian shadowing
+func syntheticCampaign(rng *rand.Rand, exponent, referenceLoss, sigma float64, count int) []Measurement {
+	points := make([]Measurement, count)
+	for i := range points {
+		d := Distance(math.Pow(10, 1+3*float64(i)/float64(count-1)))
+		loss := referenceLoss + 10*exponent*math.Log10(float64(d)) + sigma*rng.NormFloat64()
+		points[i] = Measurement{Distance: d, EIRPDBm: 30, RSSIDBm: 30 - loss}
+	}
+	return points
+}
+
+func TestFitPathLossExponent(t *testing.T) {
+
+	t.Run("Recovers an exact log-distance model", func(t *testing.T) {
+		points := syntheticCampaign(rand.New(rand.NewSource(1)), 3.2, 40, 0, 50)
+
+		exponent, referenceLoss, rmse := FitPathLossExponent(points, 2.4*GHz)
+		assert.InDelta(t, 3.2, exponent, 1e-9)
+		assert.InDelta(t, 40.0, referenceLoss, 1e-9)
+		assert.InDelta(t, 0.0, rmse, 1e-9)
+	})
+
+	t.Run("Recovers the exponent from shadowed measurements", func(t *testing.T) {
+		points := syntheticCampaign(rand.New(rand.NewSource(1)), 2.8, 40, 6, 2000)
+
+		exponent, referenceLoss, rmse := FitPathLossExponent(points, 2.4*GHz)
+		assert.InDelta(t, 2.8, exponent, 0.05)
+		assert.InDelta(t, 40.0, referenceLoss, 1.0)
+		assert.InDelta(t, 6.0, rmse, 0.3)
+	})
+
+	t.Run("Anchors single distance measurements to free space", func(t *testing.T) {
+		loss := CalculateLogDistancePathLoss(2.4*GHz, 100*M, FitReferenceDistance, 3)
+		points := []Measurement{{Distance: 100 * M, EIRPDBm: 20, RSSIDBm: 20 - float64(loss)}}
+
+		exponent, referenceLoss, rmse := FitPathLossExponent(points, 2.4*GHz)
+		assert.InDelta(t, 3.0, exponent, 1e-9)
+		assert.InDelta(t, float64(CalculateFreeSpacePathLoss(2.4*GHz, FitReferenceDistance)), referenceLoss, 1e-9)
+		assert.InDelta(t, 0.0, rmse, 1e-9)
+	})
+
+	t.Run("Requires measurements", func(t *testing.T) {
+		exponent, _, _ := FitPathLossExponent(nil, 2.4*GHz)
+		assert.True(t, math.IsNaN(exponent))
+	})
+
+	t.Run("Rejects degenerate measurements", func(t *testing.T) {
+		// Measurements at the reference distance cannot determine the exponent
+		atReference := []Measurement{
+			{Distance: FitReferenceDistance, EIRPDBm: 20, RSSIDBm: -20},
+			{Distance: FitReferenceDistance, EIRPDBm: 20, RSSIDBm: -21},
+		}
+
+		// Non-positive distances have no log distance
+		points := syntheticCampaign(rand.New(rand.NewSource(1)), 3, 40, 0, 10)
+		zero := append([]Measurement{{Distance: 0, EIRPDBm: 20, RSSIDBm: -20}}, points...)
+		negative := append([]Measurement{{Distance: -10, EIRPDBm: 20, RSSIDBm: -20}}, points...)
+
+		for _, measurements := range [][]Measurement{atReference, zero, negative} {
+			exponent, referenceLoss, rmse := FitPathLossExponent(measurements, 2.4*GHz)
+			assert.True(t, math.IsNaN(exponent))
+			assert.True(t, math.IsNaN(referenceLoss))
+			assert.True(t, math.IsNaN(rmse))
+		}
+	})
+}
+
+func TestCalibrateModel(t *testing.T) {
+
+	t.Run("Calibrated model reproduces a single campaign", func(t *testing.T) {
+		points := syntheticCampaign(rand.New(rand.NewSource(1)), 3.2, 40, 0, 50)
+
+		model, err := CalibrateModel([][]Measurement{points}, 2.4*GHz)
+		assert.Nil(t, err)
+		assert.InDelta(t, 3.2, model.Exponent, 1e-9)
+
+		for _, p := range points {
+			assert.InDelta(t, float64(p.PathLoss()), float64(model.PathLoss(2.4*GHz, Geometry{Distance: p.Distance})), 1e-9)
+		}
+	})
+
+	t.Run("Campaigns are weighted equally", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1))
+		sparse := syntheticCampaign(rng, 2, 40, 0, 20)
+		dense := syntheticCampaign(rng, 4, 40, 0, 500)
+
+		model, err := CalibrateModel([][]Measurement{sparse, dense}, 2.4*GHz)
+		assert.Nil(t, err)
+		// Approximately the mean of the campaign exponents, as the campaigns sample distances similarly
+		assert.InDelta(t, 3.0, model.Exponent, 0.1)
+		assert.InDelta(t, 40.0, model.ReferenceLossDB, 2.0)
+
+		// Merging the measurements instead favours the dense campaign
+		merged, _, _ := FitPathLossExponent(append(sparse, dense...), 2.4*GHz)
+		assert.True(t, merged > 3.8)
+	})
+
+	t.Run("Rejects missing measurements", func(t *testing.T) {
+		_, err := CalibrateModel(nil, 2.4*GHz)
+		assert.NotNil(t, err)
+
+		_, err = CalibrateModel([][]Measurement{syntheticCampaign(rand.New(rand.NewSource(1)), 3, 40, 0, 10), {}}, 2.4*GHz)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Rejects measurements that do not determine a model", func(t *testing.T) {
+		atReference := []Measurement{{Distance: FitReferenceDistance, EIRPDBm: 20, RSSIDBm: -20}}
+		_, err := CalibrateModel([][]Measurement{atReference}, 2.4*GHz)
+		assert.NotNil(t, err)
+
+		zero := []Measurement{{Distance: 0, EIRPDBm: 20, RSSIDBm: -20}}
+		_, err = CalibrateModel([][]Measurement{syntheticCampaign(rand.New(rand.NewSource(1)), 3, 40, 0, 10), zero}, 2.4*GHz)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Calibrated models keep a zero reference loss", func(t *testing.T) {
+		// A fitted reference loss of zero is distinct from the free space default
+		points := syntheticCampaign(rand.New(rand.NewSource(1)), 2.5, 0, 0, 20)
+
+		model, err := CalibrateModel([][]Measurement{points}, 2.4*GHz)
+		assert.Nil(t, err)
+		assert.True(t, model.UseReferenceLoss)
+		assert.InDelta(t, 0.0, model.ReferenceLossDB, 1e-9)
+
+		for _, p := range points {
+			assert.InDelta(t, float64(p.PathLoss()), float64(model.PathLoss(2.4*GHz, Geometry{Distance: p.Distance})), 1e-9)
+		}
+
+		// Without a reference loss free space is used at the reference distance
+		freeSpace := LogDistanceModel{Reference: FitReferenceDistance, Exponent: 2.5}
+		assert.InDelta(t, float64(CalculateFreeSpacePathLoss(2.4*GHz, FitReferenceDistance)), float64(freeSpace.PathLoss(2.4*GHz, Geometry{Distance: FitReferenceDistance})), allowedError)
+	})
+}
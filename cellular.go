@@ -0,0 +1,122 @@
+package rf
+
+import (
+	"math"
+)
+
+// Cellular planning helpers
+
+// CarrierToInterference calculates the carrier to interference ratio (C/I) in dB from the desired received
+// power and the received powers of each interferer (all in dBm)
+// https://en.wikipedia.org/wiki/Signal-to-interference_ratio
+func CarrierToInterference(desiredRxDBm float64, interferers []float64) float64 {
+	return desiredRxDBm - AddDecibels(interferers...)
+}
+
+// hexagonalInterferers is the number of first tier co-channel interferers in a hexagonal cell layout
+const hexagonalInterferers = 6
+
+// maxClusterSize is the largest cluster size considered when searching for a reuse pattern
+const maxClusterSize = 1000
+
+// ReuseDistance calculates the co-channel reuse distance for a hexagonal cell layout with the provided
+// cell radius and cluster size, using D/R = √(3N)
+// https://en.wikipedia.org/wiki/Frequency_reuse
+func ReuseDistance(cellRadius Distance, clusterSize int) Distance {
+	return cellRadius * Distance(math.Sqrt(3*float64(clusterSize)))
+}
+
+// ClusterSizeForCI finds the smallest valid hexagonal cluster size (N = i² + ij + j²) achieving the target C/I
+// in dB with six first tier interferers and the provided path loss exponent, where C/I = (D/R)^n / 6.
+// Returns 0 where no cluster size up to maxClusterSize achieves the target.
+func ClusterSizeForCI(targetCIdB float64, pathLossExponent float64) int {
+	if pathLossExponent <= 0 {
+		return 0
+	}
+
+	for n := 1; n <= maxClusterSize; n++ {
+		if !validClusterSize(n) {
+			continue
+		}
+
+		q := math.Sqrt(3 * float64(n))
+		ci := 10 * math.Log10(math.Pow(q, pathLossExponent)/hexagonalInterferers)
+		if ci >= targetCIdB {
+			return n
+		}
+	}
+
+	return 0
+}
+
+// validClusterSize checks whether n can be expressed as i² + ij + j² for non-negative integers i and j
+func validClusterSize(n int) bool {
+	for i := 0; i*i <= n; i++ {
+		for j := 0; i*i+i*j+j*j <= n; j++ {
+			if i*i+i*j+j*j == n {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Adjacent channel integration parameters
+const (
+	// adjacentChannelSteps is the number of integration steps used per integration window
+	adjacentChannelSteps = 10000
+	// adjacentChannelTolerance is the relative change in coupled power below which the integration window stops growing
+	adjacentChannelTolerance = 1e-4
+	// adjacentChannelMaxWindows is the maximum number of times the integration window is doubled
+	adjacentChannelMaxWindows = 16
+)
+
+// AdjacentChannelInterference calculates the interference power in dBm received from a transmitter offset in
+// frequency from the receiver by integrating the product of the transmitter emission mask and the receiver
+// selectivity. txMask returns the transmitter power spectral density relative to the total transmit power (dB/Hz)
+// at an offset from the transmitter carrier, and rxSelectivity returns the receiver response (dB, 0 in band) at an
+// offset from the receiver centre frequency. The integration window starts at twice the offset either side of the
+// transmitter and is widened until the coupled power converges. Co-channel (zero offset) interference is not attenuated.
+func AdjacentChannelInterference(txPowerDBm float64, freqOffset Frequency, txMask, rxSelectivity func(Frequency) float64) float64 {
+	if freqOffset == 0 {
+		return txPowerDBm + rxSelectivity(0)
+	}
+
+	// Transmit spectrum (relative to the transmitter carrier) filtered by the offset receiver
+	coupled := func(f float64) float64 {
+		return math.Pow(10, (txMask(Frequency(f))+rxSelectivity(Frequency(f)-freqOffset))/10)
+	}
+
+	span := 2 * math.Abs(float64(freqOffset))
+	power := integrate(coupled, -span, span, adjacentChannelSteps)
+
+	for i := 0; i < adjacentChannelMaxWindows; i++ {
+		// Add the power in the newly covered band edges
+		added := integrate(coupled, -2*span, -span, adjacentChannelSteps) + integrate(coupled, span, 2*span, adjacentChannelSteps)
+		power += added
+		span *= 2
+
+		if added <= power*adjacentChannelTolerance {
+			break
+		}
+	}
+
+	return txPowerDBm + 10*math.Log10(power)
+}
+
+// AreaCoverageProbability calculates the fraction of locations within a cell that receive at least the threshold
+// signal given the probability of coverage at the cell edge, the path loss exponent and the log-normal shadowing
+// standard deviation in dB, using the Jakes/Reudink formula. Probabilities are fractions between 0 and 1, and NaN
+// is returned for invalid inputs.
+// https://en.wikipedia.org/wiki/Cell_coverage_area
+func AreaCoverageProbability(edgeReliability float64, pathLossExponent float64, sigmaDB float64) float64 {
+	if !(edgeReliability > 0 && edgeReliability < 1) || pathLossExponent <= 0 || sigmaDB <= 0 {
+		return math.NaN()
+	}
+
+	// Edge reliability is ½(1 - erf(a)), b is the shadowing normalised path loss slope
+	a := math.Erfinv(1 - 2*edgeReliability)
+	b := 10 * pathLossExponent * math.Log10(math.E) / (sigmaDB * math.Sqrt2)
+
+	return 0.5 * (1 - math.Erf(a) + math.Exp((1-2*a*b)/(b*b))*(1-math.Erf((1-a*b)/b)))
+}
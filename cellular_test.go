@@ -0,0 +1,129 @@
+package rf
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// exampleTxMask is a 1MHz wide emission with skirts falling 40dB/MHz to a -80dBc floor
+func exampleTxMask(f Frequency) float64 {
+	excess := math.Max(0, math.Abs(float64(f))-0.5e+6) / 1e+6
+	return -60 - math.Min(40*excess, 80)
+}
+
+// exampleRxSelectivity is a 1MHz wide receiver with skirts falling 50dB/MHz to a -100dB floor
+func exampleRxSelectivity(f Frequency) float64 {
+	excess := math.Max(0, math.Abs(float64(f))-0.5e+6) / 1e+6
+	return -math.Min(50*excess, 100)
+}
+
+func TestCellular(t *testing.T) {
+
+	t.Run("Can calculate C/I for a single interferer", func(t *testing.T) {
+		assert.InDelta(t, 20.0, CarrierToInterference(-70, []float64{-90}), allowedError)
+	})
+
+	t.Run("Equal interferers degrade C/I", func(t *testing.T) {
+		single := CarrierToInterference(-70, []float64{-90})
+		triple := CarrierToInterference(-70, []float64{-90, -90, -90})
+		assert.InDelta(t, 4.77, single-triple, 0.01)
+	})
+
+	t.Run("C/I is unbounded without interferers", func(t *testing.T) {
+		assert.True(t, math.IsInf(CarrierToInterference(-70, nil), 1))
+	})
+
+	t.Run("Can calculate reuse distances for canonical cluster sizes", func(t *testing.T) {
+		tests := []struct {
+			clusterSize int
+			distance    float64
+		}{
+			{3, 3000},
+			{4, 3464.10},
+			{7, 4582.58},
+			{12, 6000},
+		}
+
+		for _, test := range tests {
+			assert.InDelta(t, test.distance, float64(ReuseDistance(1*Km, test.clusterSize)), 0.01, "N=%d", test.clusterSize)
+		}
+	})
+
+	t.Run("Can select cluster sizes for a target C/I", func(t *testing.T) {
+		tests := []struct {
+			target      float64
+			exponent    float64
+			clusterSize int
+		}{
+			// C/I for N = 3, 4, 7, 9, 12 with n = 4 is 11.3, 13.8, 18.7, 20.8 and 23.3dB
+			{11.0, 4, 3},
+			{13.0, 4, 4},
+			{18.0, 4, 7},
+			{22.0, 4, 12},
+			// Smaller exponents require larger clusters
+			{18.0, 3, 19},
+		}
+
+		for _, test := range tests {
+			assert.Equal(t, test.clusterSize, ClusterSizeForCI(test.target, test.exponent), "target %.1fdB n=%.1f", test.target, test.exponent)
+		}
+	})
+
+	t.Run("Only valid hexagonal cluster sizes are selected", func(t *testing.T) {
+		for _, n := range []int{1, 3, 4, 7, 9, 12, 13, 16, 19, 21} {
+			assert.True(t, validClusterSize(n), "N=%d", n)
+		}
+		for _, n := range []int{2, 5, 6, 8, 10, 11, 14} {
+			assert.False(t, validClusterSize(n), "N=%d", n)
+		}
+	})
+
+	t.Run("Adjacent channel interference falls with the guard band", func(t *testing.T) {
+		// Overlapping channels couple almost all transmit power
+		overlap := AdjacentChannelInterference(30, 0.1*MHz, exampleTxMask, exampleRxSelectivity)
+		assert.InDelta(t, 30.0, overlap, 1.0)
+
+		// Directly adjacent channels couple through the overlapping skirts
+		adjacent := AdjacentChannelInterference(30, 1*MHz, exampleTxMask, exampleRxSelectivity)
+		assert.True(t, adjacent < overlap-5, "adjacent: %.2f", adjacent)
+
+		// A wide guard band reaches the mask and selectivity floors
+		guarded := AdjacentChannelInterference(30, 5*MHz, exampleTxMask, exampleRxSelectivity)
+		assert.True(t, guarded < adjacent-30, "guarded: %.2f", guarded)
+		assert.True(t, guarded < -40, "guarded: %.2f", guarded)
+
+		previous := overlap
+		for _, offset := range []Frequency{0.5 * MHz, 1 * MHz, 2 * MHz, 5 * MHz} {
+			aci := AdjacentChannelInterference(30, offset, exampleTxMask, exampleRxSelectivity)
+			assert.True(t, aci < previous, "offset %.1fMHz", offset/MHz)
+			previous = aci
+		}
+	})
+
+	t.Run("Adjacent channel interference is symmetric in offset", func(t *testing.T) {
+		above := AdjacentChannelInterference(30, 2*MHz, exampleTxMask, exampleRxSelectivity)
+		below := AdjacentChannelInterference(30, -2*MHz, exampleTxMask, exampleRxSelectivity)
+		assert.InDelta(t, above, below, 1e-6)
+
+		assert.InDelta(t, 30.0, AdjacentChannelInterference(30, 0, exampleTxMask, exampleRxSelectivity), allowedError)
+	})
+
+	t.Run("Can calculate area coverage from edge reliability", func(t *testing.T) {
+		// Published Jakes/Reudink curves give ~90% area coverage for 75% edge reliability with n = 4 and σ = 8dB
+		assert.InDelta(t, 0.907, AreaCoverageProbability(0.75, 4, 8), allowedError)
+
+		// Area coverage rises as shadowing falls
+		assert.True(t, AreaCoverageProbability(0.5, 4, 4) > AreaCoverageProbability(0.5, 4, 12))
+
+		// Area coverage always exceeds edge coverage
+		assert.True(t, AreaCoverageProbability(0.9, 3, 8) > 0.9)
+	})
+
+	t.Run("Area coverage rejects invalid inputs", func(t *testing.T) {
+		assert.True(t, math.IsNaN(AreaCoverageProbability(1, 4, 8)))
+		assert.True(t, math.IsNaN(AreaCoverageProbability(0.75, 0, 8)))
+		assert.True(t, math.IsNaN(AreaCoverageProbability(0.75, 4, 0)))
+	})
+}
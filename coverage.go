@@ -0,0 +1,346 @@
+package rf
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"sync"
+)
+
+// Propagation models and coverage prediction
+
+// Geometry describes the path between a transmitter and receiver for use by propagation models
+type Geometry struct {
+	// Distance is the ground distance between the transmitter and receiver
+	Distance Distance
+	// TxHeight is the transmitter antenna height above ground in meters
+	TxHeight float64
+	// RxHeight is the receiver antenna height above ground in meters
+	RxHeight float64
+}
+
+// PropagationModel is implemented by models that predict path loss for a given frequency and geometry
+type PropagationModel interface {
+	PathLoss(freq Frequency, geom Geometry) Attenuation
+}
+
+// FreeSpaceModel is a PropagationModel using the Free Space Path Loss
+type FreeSpaceModel struct{}
+
+// PathLoss calculates the Free Space Path Loss for the provided geometry
+func (m FreeSpaceModel) PathLoss(freq Frequency, geom Geometry) Attenuation {
+	return CalculateFreeSpacePathLoss(freq, geom.Distance)
+}
+
+// LogDistanceModel is a PropagationModel using the log-distance path loss model
+type LogDistanceModel struct {
+	// Reference is the distance to which free space loss applies
+	Reference Distance
+	// Exponent is the path loss exponent beyond the reference distance
+	Exponent float64
+	// ReferenceLossDB is the path loss at the reference distance in dB (for example from measurements), used
+	// where UseReferenceLoss is set
+	ReferenceLossDB float64
+	// UseReferenceLoss selects ReferenceLossDB at the reference distance, otherwise free space loss is used
+	UseReferenceLoss bool
+}
+
+// PathLoss calculates the log-distance path loss for the provided geometry
+func (m LogDistanceModel) PathLoss(freq Frequency, geom Geometry) Attenuation {
+	if m.UseReferenceLoss {
+		return Attenuation(m.ReferenceLossDB + 10*m.Exponent*math.Log10(float64(geom.Distance/m.Reference)))
+	}
+	return CalculateLogDistancePathLoss(freq, geom.Distance, m.Reference, m.Exponent)
+}
+
+// IsModelSymmetric checks whether a propagation model is reciprocal in the endpoint heights, ie. the path loss is
+// unchanged (within tol dB) when the transmitter and receiver heights of the provided geometry are swapped
+func IsModelSymmetric(model PropagationModel, freq Frequency, geom Geometry, tol float64) bool {
+	swapped := geom
+	swapped.TxHeight, swapped.RxHeight = geom.RxHeight, geom.TxHeight
+
+	return math.Abs(float64(model.PathLoss(freq, geom)-model.PathLoss(freq, swapped))) <= tol
+}
+
+// PathLossSlope calculates the effective path loss exponent of a propagation model between two distances, ie. the
+// slope of the path loss in dB per decade of distance divided by 10 (2 for free space)
+func PathLossSlope(model PropagationModel, freq Frequency, geom Geometry, d1, d2 Distance) float64 {
+	near, far := geom, geom
+	near.Distance, far.Distance = d1, d2
+
+	Δloss := float64(model.PathLoss(freq, far) - model.PathLoss(freq, near))
+
+	return Δloss / (10 * math.Log10(float64(d2/d1)))
+}
+
+// CachedModel is a PropagationModel wrapper that memoizes path loss results, for example where the same
+// distance / frequency pairs recur when computing coverage grids. Distances and frequencies are quantized
+// into buckets (disabled for zero bucket sizes) and the wrapped model is evaluated at the bucket centre,
+// so results are independent of query order. CachedModel is safe for concurrent use.
+type CachedModel struct {
+	model           PropagationModel
+	distanceBucket  Distance
+	frequencyBucket Frequency
+
+	mu    sync.RWMutex
+	cache map[cachedModelKey]Attenuation
+}
+
+// cachedModelKey identifies a quantized path loss query
+type cachedModelKey struct {
+	distance, frequency float64
+	txHeight, rxHeight  float64
+}
+
+// NewCachedModel creates a CachedModel wrapping the provided model with the provided bucket sizes
+func NewCachedModel(model PropagationModel, distanceBucket Distance, frequencyBucket Frequency) *CachedModel {
+	return &CachedModel{
+		model:           model,
+		distanceBucket:  distanceBucket,
+		frequencyBucket: frequencyBucket,
+		cache:           make(map[cachedModelKey]Attenuation),
+	}
+}
+
+// PathLoss calculates (or fetches from the cache) the path loss of the wrapped model
+func (m *CachedModel) PathLoss(freq Frequency, geom Geometry) Attenuation {
+	freq = Frequency(quantize(float64(freq), float64(m.frequencyBucket)))
+	geom.Distance = Distance(quantize(float64(geom.Distance), float64(m.distanceBucket)))
+
+	key := cachedModelKey{float64(geom.Distance), float64(freq), geom.TxHeight, geom.RxHeight}
+
+	m.mu.RLock()
+	loss, ok := m.cache[key]
+	m.mu.RUnlock()
+	if ok {
+		return loss
+	}
+
+	loss = m.model.PathLoss(freq, geom)
+
+	m.mu.Lock()
+	m.cache[key] = loss
+	m.mu.Unlock()
+
+	return loss
+}
+
+// Len returns the number of cached path loss results
+func (m *CachedModel) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.cache)
+}
+
+// quantize rounds a value to the centre of a bucket of the provided size, or returns the value where size is zero
+func quantize(value, size float64) float64 {
+	if size <= 0 {
+		return value
+	}
+	return math.Floor(value/size+0.5) * size
+}
+
+// Model crossover search parameters
+const (
+	// ModelCrossoverMinDistance is the shortest distance considered when searching for model crossovers
+	ModelCrossoverMinDistance = 1 * M
+	// modelCrossoverSteps is the number of log spaced distances scanned to bracket model crossovers
+	modelCrossoverSteps = 100
+)
+
+// ModelCrossoverDistance finds the shortest distance between ModelCrossoverMinDistance and searchMax at which two
+// propagation models predict equal path loss, for example to choose the boundary between near and far models.
+// Distances are scanned logarithmically to bracket the crossover, which is then refined using the shared root finder.
+func ModelCrossoverDistance(a, b PropagationModel, freq Frequency, geom Geometry, searchMax Distance) (Distance, error) {
+	return ModelCrossoverDistanceWithOptions(a, b, freq, geom, searchMax, DefaultSolverOptions)
+}
+
+// ModelCrossoverDistanceWithOptions finds the crossover distance as ModelCrossoverDistance, using the provided solver
+// options (with the tolerance in log10 meters) to refine the crossover
+func ModelCrossoverDistanceWithOptions(a, b PropagationModel, freq Frequency, geom Geometry, searchMax Distance, opts SolverOptions) (Distance, error) {
+	if searchMax <= ModelCrossoverMinDistance {
+		return 0, fmt.Errorf("Crossover search distance must exceed %.2fm (distance: %.2fm)", ModelCrossoverMinDistance, searchMax)
+	}
+
+	// Difference in predicted loss by log distance
+	difference := func(logDistance float64) float64 {
+		geom.Distance = Distance(math.Pow(10, logDistance))
+		return float64(a.PathLoss(freq, geom) - b.PathLoss(freq, geom))
+	}
+
+	lo, hi := math.Log10(float64(ModelCrossoverMinDistance)), math.Log10(float64(searchMax))
+	step := (hi - lo) / modelCrossoverSteps
+
+	previous := difference(lo)
+	if previous == 0 {
+		return ModelCrossoverMinDistance, nil
+	}
+
+	for i := 1; i <= modelCrossoverSteps; i++ {
+		x := lo + float64(i)*step
+		current := difference(x)
+
+		if current == 0 || math.Signbit(current) != math.Signbit(previous) {
+			logDistance, err := Brent(difference, x-step, x, opts)
+			if err != nil {
+				return 0, fmt.Errorf("Model crossover search failed: %w", err)
+			}
+			return Distance(math.Pow(10, logDistance)), nil
+		}
+
+		previous = current
+	}
+
+	return 0, fmt.Errorf("No model crossover found below %.2fm", searchMax)
+}
+
+// BlendedModel is a PropagationModel using the Near model below the Crossover distance and the Far model above it,
+// for example free space near the transmitter and an empirical model further away. Where Transition is non-zero the
+// losses (in dB) are blended linearly with distance over a region of that width centred on the crossover.
+type BlendedModel struct {
+	// Near is the model used below the crossover
+	Near PropagationModel
+	// Far is the model used above the crossover
+	Far PropagationModel
+	// Crossover is the distance at which the models are switched
+	Crossover Distance
+	// Transition is the width of the blended region around the crossover
+	Transition Distance
+}
+
+// NewBlendedModel creates a BlendedModel switching between the near and far models where they predict equal loss
+// (see ModelCrossoverDistance) for the provided frequency and geometry
+func NewBlendedModel(near, far PropagationModel, freq Frequency, geom Geometry, searchMax, transition Distance) (BlendedModel, error) {
+	crossover, err := ModelCrossoverDistance(near, far, freq, geom, searchMax)
+	if err != nil {
+		return BlendedModel{}, err
+	}
+
+	return BlendedModel{Near: near, Far: far, Crossover: crossover, Transition: transition}, nil
+}
+
+// PathLoss calculates the path loss of the near or far model, or a blend of both within the transition region
+func (m BlendedModel) PathLoss(freq Frequency, geom Geometry) Attenuation {
+	start, end := m.Crossover-m.Transition/2, m.Crossover+m.Transition/2
+
+	switch {
+	case geom.Distance >= end:
+		return m.Far.PathLoss(freq, geom)
+	case geom.Distance <= start:
+		return m.Near.PathLoss(freq, geom)
+	}
+
+	weight := Attenuation((geom.Distance - start) / m.Transition)
+	return (1-weight)*m.Near.PathLoss(freq, geom) + weight*m.Far.PathLoss(freq, geom)
+}
+
+// TxSite describes a transmitter location and configuration
+type TxSite struct {
+	// Lat and Lon are the transmitter location in degrees
+	Lat, Lon float64
+	// Height is the antenna height above ground in meters
+	Height float64
+	// Frequency is the transmit frequency
+	Frequency Frequency
+	// PowerDBm is the transmit power in dBm
+	PowerDBm float64
+	// GainDBi is the transmit antenna gain in dBi
+	GainDBi float64
+}
+
+// GridBounds describes the area over which coverage is computed
+type GridBounds struct {
+	// MinLat, MinLon, MaxLat and MaxLon are the grid edges in degrees
+	MinLat, MinLon, MaxLat, MaxLon float64
+	// RxHeight is the receiver antenna height above ground in meters
+	RxHeight float64
+}
+
+// CoverageGrid computes the predicted received power (dBm) over a resolution x resolution grid
+// using the provided propagation model. The grid is indexed [latitude][longitude] from the minimum
+// bounds with each value computed at the centre of the corresponding cell.
+func CoverageGrid(tx TxSite, model PropagationModel, bounds GridBounds, resolution int) [][]float64 {
+	grid, _ := CoverageGridContext(context.Background(), tx, model, bounds, resolution)
+	return grid
+}
+
+// CoverageGridContext computes a coverage grid as CoverageGrid, checking the provided context between rows
+// and returning early with the context error if it is cancelled
+func CoverageGridContext(ctx context.Context, tx TxSite, model PropagationModel, bounds GridBounds, resolution int) ([][]float64, error) {
+	grid := make([][]float64, resolution)
+
+	Δlat := (bounds.MaxLat - bounds.MinLat) / float64(resolution)
+	Δlon := (bounds.MaxLon - bounds.MinLon) / float64(resolution)
+
+	for i := range grid {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		grid[i] = make([]float64, resolution)
+		lat := bounds.MinLat + (float64(i)+0.5)*Δlat
+
+		for j := range grid[i] {
+			lon := bounds.MinLon + (float64(j)+0.5)*Δlon
+
+			geom := Geometry{
+				Distance: CalculateDistance(tx.Lat, tx.Lon, lat, lon, R),
+				TxHeight: tx.Height,
+				RxHeight: bounds.RxHeight,
+			}
+
+			loss := model.PathLoss(tx.Frequency, geom)
+			grid[i][j] = tx.PowerDBm + tx.GainDBi - float64(loss)
+		}
+	}
+
+	return grid, nil
+}
+
+// RenderCoverageGrid renders a coverage grid as a PNG heat map with one pixel per cell.
+// Received power values are mapped onto a blue (min) to red (max) gradient, with values
+// outside of the min/max range clamped. The grid is drawn with the minimum latitude at the bottom.
+func RenderCoverageGrid(w io.Writer, grid [][]float64, min, max float64) error {
+	if len(grid) == 0 || len(grid[0]) == 0 {
+		return fmt.Errorf("Coverage grid must contain at least one cell")
+	}
+	if max <= min {
+		return fmt.Errorf("Coverage grid maximum must be greater than minimum (min: %.2f max: %.2f)", min, max)
+	}
+
+	height, width := len(grid), len(grid[0])
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for i, row := range grid {
+		if len(row) != width {
+			return fmt.Errorf("Coverage grid rows must be of equal length (row %d: %d expected: %d)", i, len(row), width)
+		}
+		for j, v := range row {
+			img.Set(j, height-1-i, coverageColour((v-min)/(max-min)))
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+// coverageColour maps a normalised value (0-1) onto a blue-cyan-green-yellow-red gradient
+func coverageColour(v float64) color.RGBA {
+	clamp := func(x float64) uint8 {
+		return uint8(math.Max(0, math.Min(1, x)) * 255)
+	}
+
+	v = math.Max(0, math.Min(1, v))
+
+	return color.RGBA{
+		R: clamp(4*v - 2),
+		G: clamp(math.Min(4*v, 4-4*v)),
+		B: clamp(2 - 4*v),
+		A: 255,
+	}
+}
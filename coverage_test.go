@@ -0,0 +1,342 @@
+package rf
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// cancellingModel is a free space model that cancels a context after a number of path loss calculations
+type cancellingModel struct {
+	calls, limit int
+	cancel       context.CancelFunc
+}
+
+func (m *cancellingModel) PathLoss(freq Frequency, geom Geometry) Attenuation {
+	m.calls++
+	if m.calls == m.limit {
+		m.cancel()
+	}
+	return FreeSpaceModel{}.PathLoss(freq, geom)
+}
+
+// expensiveModel is a free space model with additional computational cost for benchmarking
+type expensiveModel struct{}
+
+func (m expensiveModel) PathLoss(freq Frequency, geom Geometry) Attenuation {
+	loss := Attenuation(0)
+	for i := 0; i < 1000; i++ {
+		loss += CalculateFreeSpacePathLoss(freq, geom.Distance)
+	}
+	return loss / 1000
+}
+
+// asymmetricModel is a free space model with an additional loss depending on the transmitter height
+type asymmetricModel struct{}
+
+func (m asymmetricModel) PathLoss(freq Frequency, geom Geometry) Attenuation {
+	return CalculateFreeSpacePathLoss(freq, geom.Distance) + Attenuation(geom.TxHeight/10)
+}
+
+func TestCoverage(t *testing.T) {
+
+	tx := TxSite{Lat: 0.0, Lon: 0.0, Height: 10.0, Frequency: 433 * MHz, PowerDBm: 20.0, GainDBi: 2.0}
+	bounds := GridBounds{MinLat: -0.1, MinLon: -0.1, MaxLat: 0.1, MaxLon: 0.1, RxHeight: 1.0}
+
+	t.Run("Free space model matches free space path loss", func(t *testing.T) {
+		loss := FreeSpaceModel{}.PathLoss(433*MHz, Geometry{Distance: 1 * Km})
+		assert.InDelta(t, float64(CalculateFreeSpacePathLoss(433*MHz, 1*Km)), float64(loss), allowedError)
+	})
+
+	t.Run("Coverage grid is symmetric around the transmitter", func(t *testing.T) {
+		n := 6
+		grid := CoverageGrid(tx, FreeSpaceModel{}, bounds, n)
+
+		assert.Len(t, grid, n)
+		for i := 0; i < n; i++ {
+			assert.Len(t, grid[i], n)
+			for j := 0; j < n; j++ {
+				assert.InDelta(t, grid[i][j], grid[n-1-i][j], allowedError, "index %d %d", i, j)
+				assert.InDelta(t, grid[i][j], grid[i][n-1-j], allowedError, "index %d %d", i, j)
+				assert.InDelta(t, grid[i][j], grid[j][i], 0.01, "index %d %d", i, j)
+			}
+		}
+	})
+
+	t.Run("Coverage grid received power decreases away from the transmitter", func(t *testing.T) {
+		n := 6
+		grid := CoverageGrid(tx, FreeSpaceModel{}, bounds, n)
+
+		for i := 0; i < n/2-1; i++ {
+			assert.True(t, grid[i][i] < grid[i+1][i+1], "index %d", i)
+		}
+
+		// Cell centres are offset by half a cell from the transmitter
+		d := CalculateDistance(tx.Lat, tx.Lon, 0.1/6, 0.1/6, R)
+		expected := tx.PowerDBm + tx.GainDBi - float64(CalculateFreeSpacePathLoss(433*MHz, d))
+		assert.InDelta(t, expected, grid[n/2][n/2], allowedError)
+	})
+
+	t.Run("Can render a coverage grid to a PNG", func(t *testing.T) {
+		grid := CoverageGrid(tx, FreeSpaceModel{}, GridBounds{MinLat: -0.1, MinLon: -0.2, MaxLat: 0.1, MaxLon: 0.2}, 8)
+		grid = grid[:5]
+
+		buffer := bytes.NewBuffer([]byte{})
+		err := RenderCoverageGrid(buffer, grid, -100, -50)
+		assert.Nil(t, err)
+
+		img, err := png.Decode(buffer)
+		assert.Nil(t, err)
+		assert.Equal(t, 8, img.Bounds().Dx())
+		assert.Equal(t, 5, img.Bounds().Dy())
+	})
+
+	t.Run("Rendering rejects invalid coverage grids", func(t *testing.T) {
+		buffer := bytes.NewBuffer([]byte{})
+
+		err := RenderCoverageGrid(buffer, [][]float64{}, -100, -50)
+		assert.NotNil(t, err)
+
+		err = RenderCoverageGrid(buffer, [][]float64{{1, 2}, {1}}, -100, -50)
+		assert.NotNil(t, err)
+
+		err = RenderCoverageGrid(buffer, [][]float64{{1, 2}}, -50, -100)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Coverage colours span blue to red", func(t *testing.T) {
+		assert.Equal(t, uint8(255), coverageColour(0).B)
+		assert.Equal(t, uint8(0), coverageColour(0).R)
+		assert.Equal(t, uint8(255), coverageColour(1).R)
+		assert.Equal(t, uint8(0), coverageColour(1).B)
+		assert.Equal(t, uint8(255), coverageColour(0.5).G)
+	})
+
+	t.Run("Coverage grid computation can be cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		model := &cancellingModel{limit: 25, cancel: cancel}
+
+		grid, err := CoverageGridContext(ctx, tx, model, bounds, 10)
+		assert.Equal(t, context.Canceled, err)
+		assert.Nil(t, grid)
+
+		// Cancellation is detected at the next row
+		assert.Equal(t, 30, model.calls)
+	})
+
+	t.Run("Coverage grid computation completes without cancellation", func(t *testing.T) {
+		grid, err := CoverageGridContext(context.Background(), tx, FreeSpaceModel{}, bounds, 6)
+		assert.Nil(t, err)
+		assert.Equal(t, CoverageGrid(tx, FreeSpaceModel{}, bounds, 6), grid)
+	})
+
+	t.Run("Cached model matches the underlying model", func(t *testing.T) {
+		cached := NewCachedModel(FreeSpaceModel{}, 0, 0)
+
+		for _, d := range []Distance{10, 100, 1000, 100, 10} {
+			geom := Geometry{Distance: d}
+			assert.Equal(t, FreeSpaceModel{}.PathLoss(433*MHz, geom), cached.PathLoss(433*MHz, geom))
+		}
+		assert.Equal(t, 3, cached.Len())
+
+		grid := CoverageGrid(tx, cached, bounds, 10)
+		assert.Equal(t, CoverageGrid(tx, FreeSpaceModel{}, bounds, 10), grid)
+	})
+
+	t.Run("Cached model quantizes distance and frequency", func(t *testing.T) {
+		cached := NewCachedModel(FreeSpaceModel{}, 10*M, 1*MHz)
+
+		expected := FreeSpaceModel{}.PathLoss(433*MHz, Geometry{Distance: 1000})
+		assert.Equal(t, expected, cached.PathLoss(433.2*MHz, Geometry{Distance: 1004}))
+		assert.Equal(t, expected, cached.PathLoss(432.9*MHz, Geometry{Distance: 996}))
+		assert.Equal(t, 1, cached.Len())
+
+		grid := CoverageGrid(tx, cached, bounds, 10)
+		expectedGrid := CoverageGrid(tx, FreeSpaceModel{}, bounds, 10)
+		for i := range grid {
+			for j := range grid[i] {
+				assert.InDelta(t, expectedGrid[i][j], grid[i][j], 0.05)
+			}
+		}
+	})
+
+	t.Run("Cached model is safe for concurrent use", func(t *testing.T) {
+		cached := NewCachedModel(FreeSpaceModel{}, 1*M, 0)
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				CoverageGrid(tx, cached, bounds, 10)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, CoverageGrid(tx, cached, bounds, 10), CoverageGrid(tx, cached, bounds, 10))
+	})
+}
+
+func BenchmarkCoverageGrid(b *testing.B) {
+	tx := TxSite{Lat: 0.0, Lon: 0.0, Height: 10.0, Frequency: 433 * MHz, PowerDBm: 20.0, GainDBi: 2.0}
+	bounds := GridBounds{MinLat: -0.1, MinLon: -0.1, MaxLat: 0.1, MaxLon: 0.1, RxHeight: 1.0}
+
+	b.Run("Uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			CoverageGrid(tx, expensiveModel{}, bounds, 50)
+		}
+	})
+
+	b.Run("Cached", func(b *testing.B) {
+		cached := NewCachedModel(expensiveModel{}, 10*M, 0)
+		for i := 0; i < b.N; i++ {
+			CoverageGrid(tx, cached, bounds, 50)
+		}
+	})
+}
+
+func TestModelCrossover(t *testing.T) {
+
+	t.Run("Free space and log-distance models cross at the reference distance", func(t *testing.T) {
+		logDistance := LogDistanceModel{Reference: 100 * M, Exponent: 3.5}
+
+		d, err := ModelCrossoverDistance(FreeSpaceModel{}, logDistance, 900*MHz, Geometry{}, 10*Km)
+		assert.Nil(t, err)
+		assert.InDelta(t, 100.0, float64(d), 1e-6)
+
+		// Log-distance loss exceeds free space beyond the crossover
+		far := Geometry{Distance: 2 * d}
+		assert.True(t, logDistance.PathLoss(900*MHz, far) > FreeSpaceModel{}.PathLoss(900*MHz, far))
+	})
+
+	t.Run("Models predict equal loss at the crossover", func(t *testing.T) {
+		near := LogDistanceModel{Reference: 10 * M, Exponent: 2.2}
+		far := LogDistanceModel{Reference: 200 * M, Exponent: 4}
+
+		d, err := ModelCrossoverDistance(near, far, 2.4*GHz, Geometry{}, 10*Km)
+		assert.Nil(t, err)
+
+		geom := Geometry{Distance: d}
+		assert.InDelta(t, float64(near.PathLoss(2.4*GHz, geom)), float64(far.PathLoss(2.4*GHz, geom)), 1e-6)
+	})
+
+	t.Run("Crossover search uses the provided solver options", func(t *testing.T) {
+		logDistance := LogDistanceModel{Reference: 100 * M, Exponent: 3.5}
+
+		d, err := ModelCrossoverDistanceWithOptions(FreeSpaceModel{}, logDistance, 900*MHz, Geometry{}, 10*Km, SolverOptions{MaxIterations: 100, Tolerance: 0.01})
+		assert.Nil(t, err)
+		assert.InDelta(t, 100.0, float64(d), 2.5)
+
+		_, err = ModelCrossoverDistanceWithOptions(FreeSpaceModel{}, logDistance, 900*MHz, Geometry{}, 10*Km, SolverOptions{})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Crossover search fails where models do not cross", func(t *testing.T) {
+		logDistance := LogDistanceModel{Reference: 1 * Km, Exponent: 3}
+
+		_, err := ModelCrossoverDistance(FreeSpaceModel{}, logDistance, 900*MHz, Geometry{}, 500*M)
+		assert.NotNil(t, err)
+
+		_, err = ModelCrossoverDistance(FreeSpaceModel{}, logDistance, 900*MHz, Geometry{}, 0)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestBlendedModel(t *testing.T) {
+
+	near := FreeSpaceModel{}
+	far := LogDistanceModel{Reference: 10 * M, Exponent: 3.5}
+
+	t.Run("Uses each model in its region", func(t *testing.T) {
+		blended := BlendedModel{Near: near, Far: far, Crossover: 500 * M, Transition: 100 * M}
+
+		for _, d := range []Distance{10 * M, 200 * M, 449 * M} {
+			geom := Geometry{Distance: d}
+			assert.Equal(t, near.PathLoss(900*MHz, geom), blended.PathLoss(900*MHz, geom), "distance %.0f", d)
+		}
+		for _, d := range []Distance{551 * M, 1 * Km, 10 * Km} {
+			geom := Geometry{Distance: d}
+			assert.Equal(t, far.PathLoss(900*MHz, geom), blended.PathLoss(900*MHz, geom), "distance %.0f", d)
+		}
+	})
+
+	t.Run("Transition is continuous", func(t *testing.T) {
+		blended := BlendedModel{Near: near, Far: far, Crossover: 500 * M, Transition: 100 * M}
+
+		for _, edge := range []Distance{450 * M, 550 * M} {
+			below := blended.PathLoss(900*MHz, Geometry{Distance: edge - 0.001})
+			above := blended.PathLoss(900*MHz, Geometry{Distance: edge + 0.001})
+			assert.InDelta(t, float64(below), float64(above), 0.001, "edge %.0f", edge)
+		}
+
+		// Midway through the transition is the mean of both models
+		geom := Geometry{Distance: 500 * M}
+		mean := (near.PathLoss(900*MHz, geom) + far.PathLoss(900*MHz, geom)) / 2
+		assert.InDelta(t, float64(mean), float64(blended.PathLoss(900*MHz, geom)), allowedError)
+	})
+
+	t.Run("Switches at the crossover without a transition", func(t *testing.T) {
+		blended, err := NewBlendedModel(near, far, 900*MHz, Geometry{}, 10*Km, 0)
+		assert.Nil(t, err)
+		assert.InDelta(t, 10.0, float64(blended.Crossover), 1e-6)
+
+		below := blended.PathLoss(900*MHz, Geometry{Distance: blended.Crossover - 0.001})
+		above := blended.PathLoss(900*MHz, Geometry{Distance: blended.Crossover + 0.001})
+		assert.InDelta(t, float64(below), float64(above), 0.01)
+
+		assert.Equal(t, far.PathLoss(900*MHz, Geometry{Distance: 1 * Km}), blended.PathLoss(900*MHz, Geometry{Distance: 1 * Km}))
+	})
+
+	t.Run("Requires a crossover", func(t *testing.T) {
+		_, err := NewBlendedModel(near, LogDistanceModel{Reference: 1 * Km, Exponent: 3}, 900*MHz, Geometry{}, 500*M, 0)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestModelSymmetry(t *testing.T) {
+
+	geom := Geometry{Distance: 5 * Km, TxHeight: 30, RxHeight: 2}
+
+	t.Run("Free space models are symmetric", func(t *testing.T) {
+		assert.True(t, IsModelSymmetric(FreeSpaceModel{}, 900*MHz, geom, allowedError))
+		assert.True(t, IsModelSymmetric(LogDistanceModel{Reference: 100 * M, Exponent: 3}, 900*MHz, geom, allowedError))
+	})
+
+	t.Run("Asymmetric models are flagged", func(t *testing.T) {
+		assert.False(t, IsModelSymmetric(asymmetricModel{}, 900*MHz, geom, allowedError))
+
+		// Within tolerance of the 2.8dB height difference
+		assert.True(t, IsModelSymmetric(asymmetricModel{}, 900*MHz, geom, 3))
+
+		// Equal heights cannot reveal asymmetry
+		assert.True(t, IsModelSymmetric(asymmetricModel{}, 900*MHz, Geometry{Distance: 5 * Km, TxHeight: 10, RxHeight: 10}, allowedError))
+	})
+}
+
+func TestPathLossSlope(t *testing.T) {
+
+	t.Run("Free space loss falls 20dB per decade", func(t *testing.T) {
+		assert.InDelta(t, 2.0, PathLossSlope(FreeSpaceModel{}, 900*MHz, Geometry{}, 1*Km, 10*Km), 1e-9)
+
+		// Independent of the distances and their order
+		assert.InDelta(t, 2.0, PathLossSlope(FreeSpaceModel{}, 900*MHz, Geometry{}, 10*Km, 20*M), 1e-9)
+	})
+
+	t.Run("Recovers the log-distance exponent", func(t *testing.T) {
+		model := LogDistanceModel{Reference: 100 * M, Exponent: 3.5}
+		assert.InDelta(t, 3.5, PathLossSlope(model, 900*MHz, Geometry{}, 1*Km, 5*Km), 1e-9)
+	})
+
+	t.Run("Blended models steepen beyond the crossover", func(t *testing.T) {
+		blended := BlendedModel{Near: FreeSpaceModel{}, Far: LogDistanceModel{Reference: 500 * M, Exponent: 4}, Crossover: 500 * M}
+
+		assert.InDelta(t, 2.0, PathLossSlope(blended, 900*MHz, Geometry{}, 10*M, 100*M), 1e-9)
+		assert.InDelta(t, 4.0, PathLossSlope(blended, 900*MHz, Geometry{}, 1*Km, 10*Km), 1e-9)
+	})
+}
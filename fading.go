@@ -0,0 +1,471 @@
+package rf
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"time"
+)
+
+// Fading distributions
+// These describe the envelope (amplitude) of a faded signal
+
+// RayleighPDF calculates the Rayleigh probability density at x for scale parameter sigma
+// https://en.wikipedia.org/wiki/Rayleigh_distribution
+func RayleighPDF(x, sigma float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return x / (sigma * sigma) * math.Exp(-x*x/(2*sigma*sigma))
+}
+
+// RayleighCDF calculates the Rayleigh cumulative probability at x for scale parameter sigma
+// https://en.wikipedia.org/wiki/Rayleigh_distribution
+func RayleighCDF(x, sigma float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return 1 - math.Exp(-x*x/(2*sigma*sigma))
+}
+
+// RicianPDF calculates the Rician probability density at x for line of sight amplitude v and scale parameter sigma
+// https://en.wikipedia.org/wiki/Rice_distribution
+func RicianPDF(x, v, sigma float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	s2 := sigma * sigma
+	// Exponentially scaled bessel function avoids overflow for large x*v
+	return x / s2 * math.Exp(-(x-v)*(x-v)/(2*s2)) * besselIScaled(0, x*v/s2)
+}
+
+// RicianCDF calculates the Rician cumulative probability at x for line of sight amplitude v and scale parameter sigma
+// using the Marcum Q-function
+// https://en.wikipedia.org/wiki/Rice_distribution
+func RicianCDF(x, v, sigma float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return 1 - MarcumQ(1, v/sigma, x/sigma)
+}
+
+// WeibullPDF calculates the Weibull probability density at x for the provided shape and scale parameters
+// https://en.wikipedia.org/wiki/Weibull_distribution
+func WeibullPDF(x, shape, scale float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return shape / scale * math.Pow(x/scale, shape-1) * math.Exp(-math.Pow(x/scale, shape))
+}
+
+// WeibullCDF calculates the Weibull cumulative probability at x for the provided shape and scale parameters
+// https://en.wikipedia.org/wiki/Weibull_distribution
+func WeibullCDF(x, shape, scale float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return 1 - math.Exp(-math.Pow(x/scale, shape))
+}
+
+// Fading generators
+// These sample the attenuation (-20log10 of the envelope) of a faded signal, such that the probability of a fade
+// of at least A dB is the corresponding CDF at an envelope of 10^(-A/20). Where rng is nil a time seeded source is used.
+
+// CalculateRaleighFading samples Rayleigh fading for scale parameter sigma
+// https://en.wikipedia.org/wiki/Rayleigh_fading
+func CalculateRaleighFading(sigma float64, rng *rand.Rand) (Attenuation, error) {
+	return CalculateRicanFading(0, sigma, rng)
+}
+
+// CalculateRicanFading samples Rician fading for line of sight amplitude v and scale parameter sigma
+// https://en.wikipedia.org/wiki/Rician_fading
+func CalculateRicanFading(v, sigma float64, rng *rand.Rand) (Attenuation, error) {
+	if v < 0 || sigma <= 0 {
+		return 0, fmt.Errorf("Rician fading requires v >= 0 and sigma > 0 (v: %.2f sigma: %.2f)", v, sigma)
+	}
+	rng = fadingSource(rng)
+
+	// Line of sight component plus complex gaussian scattered components
+	envelope := cmplx.Abs(complex(v+sigma*rng.NormFloat64(), sigma*rng.NormFloat64()))
+
+	return Attenuation(-20 * math.Log10(envelope)), nil
+}
+
+// CalculateWeibullFading samples Weibull fading for the provided shape and scale parameters
+// https://en.wikipedia.org/wiki/Weibull_fading
+func CalculateWeibullFading(shape, scale float64, rng *rand.Rand) (Attenuation, error) {
+	if shape <= 0 || scale <= 0 {
+		return 0, fmt.Errorf("Weibull fading requires positive shape and scale (shape: %.2f scale: %.2f)", shape, scale)
+	}
+	rng = fadingSource(rng)
+
+	// Inverse transform sampling of the Weibull CDF
+	envelope := scale * math.Pow(rng.ExpFloat64(), 1/shape)
+
+	return Attenuation(-20 * math.Log10(envelope)), nil
+}
+
+// fadingSource returns the provided random source, or a time seeded source where nil
+func fadingSource(rng *rand.Rand) *rand.Rand {
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return rng
+}
+
+// FadeDistribution is a statistical model of signal fading
+type FadeDistribution string
+
+// Supported fade distributions
+const (
+	// FadeRayleigh is Rayleigh (non line of sight) fading, without parameters
+	FadeRayleigh FadeDistribution = "rayleigh"
+	// FadeRician is Rician (line of sight) fading, parameterised by the K factor in dB
+	FadeRician FadeDistribution = "rician"
+	// FadeLogNormal is log-normal shadowing, parameterised by the standard deviation in dB
+	FadeLogNormal FadeDistribution = "lognormal"
+)
+
+// FadeMarginForOutage calculates the fade margin (relative to the mean received power) required for the probability
+// of the signal fading below the receiver threshold to meet outageProbability (ie. 0.01 for 1%), using the provided
+// distribution and its parameters (see the FadeDistribution constants)
+func FadeMarginForOutage(distribution FadeDistribution, outageProbability float64, params ...float64) (Attenuation, error) {
+	return FadeMarginForOutageWithOptions(distribution, outageProbability, DefaultSolverOptions, params...)
+}
+
+// FadeMarginForOutageWithOptions calculates the fade margin as FadeMarginForOutage, using the provided solver options
+// with the tolerance in log10 amplitude for Rician fading (other distributions are calculated directly)
+func FadeMarginForOutageWithOptions(distribution FadeDistribution, outageProbability float64, opts SolverOptions, params ...float64) (Attenuation, error) {
+	if outageProbability <= 0 || outageProbability >= 1 {
+		return 0, fmt.Errorf("Outage probability must be between 0 and 1 (probability: %.4g)", outageProbability)
+	}
+
+	switch distribution {
+	case FadeRayleigh:
+		// Received power is exponentially distributed, P(p < mean/M) = 1 - exp(-1/M)
+		return Attenuation(-10 * math.Log10(-math.Log(1-outageProbability))), nil
+
+	case FadeLogNormal:
+		if len(params) != 1 || params[0] < 0 {
+			return 0, fmt.Errorf("Log-normal fading requires a non-negative standard deviation in dB (params: %v)", params)
+		}
+		// Margin is the standard deviation scaled by the inverse Q-function
+		return Attenuation(params[0] * math.Sqrt2 * math.Erfinv(1-2*outageProbability)), nil
+
+	case FadeRician:
+		if len(params) != 1 {
+			return 0, fmt.Errorf("Rician fading requires a K factor in dB (params: %v)", params)
+		}
+
+		// Split unit mean power between the line of sight and scattered components
+		k := math.Pow(10, params[0]/10)
+		v, sigma := math.Sqrt(k/(k+1)), math.Sqrt(1/(2*(k+1)))
+
+		// Find the amplitude (relative to the rms amplitude) below which the signal falls for the outage probability
+		x, err := Brent(func(logX float64) float64 {
+			return RicianCDF(math.Pow(10, logX), v, sigma) - outageProbability
+		}, -6, 1, opts)
+		if err != nil {
+			return 0, fmt.Errorf("Rician fade margin search failed: %w", err)
+		}
+
+		return Attenuation(-20 * x), nil
+	}
+
+	return 0, fmt.Errorf("Unknown fade distribution %s", distribution)
+}
+
+// DiversityTechnique is a method of combining signals from multiple diversity branches
+type DiversityTechnique string
+
+// Supported diversity combining techniques
+const (
+	DiversitySelection DiversityTechnique = "selection"
+	DiversityMRC       DiversityTechnique = "mrc"
+	DiversityEGC       DiversityTechnique = "egc"
+)
+
+// DiversityOutageProbability is the outage probability at which diversity gain is evaluated
+const DiversityOutageProbability = 0.01
+
+// diversitySearchLimit is the largest combined threshold SNR (log10, relative to the mean branch SNR) considered
+// when searching for MRC and EGC diversity gain
+const diversitySearchLimit = 10.0
+
+// DiversityGain calculates the reduction in fade margin (dB) at DiversityOutageProbability from combining the
+// provided number of Rayleigh faded branches (each with equal mean power) using selection, maximal ratio (MRC)
+// or equal gain (EGC) combining. EGC is approximated from MRC by the ratio of their mean output SNRs, and branch
+// envelope correlation (0 to 1) is approximated by scaling the gain by √(1-ρ²).
+// Returns NaN for invalid branch counts, correlations or techniques.
+// https://en.wikipedia.org/wiki/Diversity_combining
+func DiversityGain(branches int, technique DiversityTechnique, correlation float64) Attenuation {
+	gain, err := DiversityGainWithOptions(branches, technique, correlation, DefaultSolverOptions)
+	if err != nil {
+		return Attenuation(math.NaN())
+	}
+	return gain
+}
+
+// DiversityGainWithOptions calculates the diversity gain as DiversityGain, using the provided solver options with the
+// tolerance in log10 SNR for MRC and EGC, returning an error for invalid parameters or where the search fails
+func DiversityGainWithOptions(branches int, technique DiversityTechnique, correlation float64, opts SolverOptions) (Attenuation, error) {
+	if branches < 1 {
+		return 0, fmt.Errorf("Diversity requires at least one branch (branches: %d)", branches)
+	}
+	if correlation < 0 || correlation > 1 {
+		return 0, fmt.Errorf("Branch correlation must be between 0 and 1 (correlation: %.2f)", correlation)
+	}
+
+	p := DiversityOutageProbability
+	l := float64(branches)
+
+	// Threshold SNR (relative to the mean branch SNR) for a single branch
+	single := -math.Log(1 - p)
+
+	var threshold float64
+	switch technique {
+	case DiversitySelection:
+		// All branches must fade below the threshold, P = (1 - exp(-γ))^L
+		threshold = -math.Log(1 - math.Pow(p, 1/l))
+
+	case DiversityMRC, DiversityEGC:
+		// Combined SNR is gamma distributed, P = 1 - exp(-γ) Σ(k=0..L-1) γ^k / k!
+		// with terms evaluated in log space to avoid overflow for large branch counts
+		outage := func(logγ float64) float64 {
+			γ := math.Pow(10, logγ)
+			sum := 0.0
+			for k := 0; k < branches; k++ {
+				lgamma, _ := math.Lgamma(float64(k + 1))
+				sum += math.Exp(float64(k)*math.Log(γ) - γ - lgamma)
+			}
+			return 1 - sum - p
+		}
+
+		// Bracket the threshold between the single branch threshold and the first decade exceeding it
+		lo, hi := math.Log10(single)-1, 2.0
+		for outage(hi) < 0 {
+			lo, hi = hi, hi+1
+			if hi > diversitySearchLimit {
+				return 0, fmt.Errorf("Diversity threshold exceeds the search limit (branches: %d)", branches)
+			}
+		}
+
+		logγ, err := Brent(outage, lo, hi, opts)
+		if err != nil {
+			return 0, fmt.Errorf("Diversity gain search failed: %w", err)
+		}
+		threshold = math.Pow(10, logγ)
+
+		if technique == DiversityEGC {
+			// EGC mean output SNR is 1+(L-1)π/4 compared to L for MRC
+			threshold *= (1 + (l-1)*π/4) / l
+		}
+
+	default:
+		return 0, fmt.Errorf("Unknown diversity technique %s", technique)
+	}
+
+	gain := 10 * math.Log10(threshold/single)
+
+	return Attenuation(gain * math.Sqrt(1-correlation*correlation)), nil
+}
+
+// besselIScaled calculates the exponentially scaled modified bessel function of the first kind exp(-z)·In(z)
+// for z >= 0 using the power series, with terms computed in log space to avoid overflow
+// https://en.wikipedia.org/wiki/Bessel_function#Modified_Bessel_functions:_I%CE%B1,_K%CE%B1
+func besselIScaled(n int, z float64) float64 {
+	if n < 0 {
+		n = -n
+	}
+	if z == 0 {
+		if n == 0 {
+			return 1
+		}
+		return 0
+	}
+
+	sum := 0.0
+	logHalfZ := math.Log(z / 2)
+	nGamma, _ := math.Lgamma(float64(n) + 1)
+
+	for k := 0; ; k++ {
+		kGamma, _ := math.Lgamma(float64(k) + 1)
+		knGamma := nGamma
+		if k > 0 {
+			knGamma, _ = math.Lgamma(float64(k+n) + 1)
+		}
+
+		term := math.Exp(float64(2*k+n)*logHalfZ - kGamma - knGamma - z)
+		sum += term
+
+		// Terms peak near k = z/2 then decay
+		if float64(k) > z/2 && term <= sum*1e-16 {
+			break
+		}
+	}
+
+	return sum
+}
+
+// MarcumQ calculates the generalised Marcum Q-function of integer order m >= 1 using its bessel series expansion,
+// returning NaN for invalid orders
+// https://en.wikipedia.org/wiki/Marcum_Q-function
+func MarcumQ(m int, a, b float64) float64 {
+	if m < 1 {
+		return math.NaN()
+	}
+	if b <= 0 {
+		return 1
+	}
+	if a <= 0 {
+		// Qm(0, b) = exp(-b²/2) Σ(k=0..m-1) (b²/2)^k / k!
+		sum, term := 0.0, 1.0
+		for k := 0; k < m; k++ {
+			if k > 0 {
+				term *= b * b / 2 / float64(k)
+			}
+			sum += term
+		}
+		return math.Exp(-b*b/2) * sum
+	}
+
+	// exp(-(a²+b²)/2)·Ik(ab) = exp(-(a-b)²/2)·exp(-ab)·Ik(ab)
+	scale := math.Exp(-(a - b) * (a - b) / 2)
+	z := a * b
+
+	if a < b {
+		// Qm(a, b) = exp(-(a²+b²)/2) Σ(k=1-m..∞) (a/b)^k Ik(ab)
+		return scale * besselSeriesScaled(z, a/b, 1-m)
+	}
+
+	// Qm(a, b) = 1 - exp(-(a²+b²)/2) Σ(k=m..∞) (b/a)^k Ik(ab)
+	return 1 - scale*besselSeriesScaled(z, b/a, m)
+}
+
+// besselRescale is the magnitude at which the unnormalised bessel recurrence is rescaled to avoid overflow
+const besselRescale = 1e+100
+
+// besselSeriesScaled calculates Σ(k=from..∞) r^k·exp(-z)·Ik(z) for z > 0 and 0 < r <= 1 (using I-k = Ik), with the
+// bessel functions of every order found in a single pass using Miller's backward recurrence
+// I(k-1) = 2k/z·Ik + I(k+1), normalised by exp(-z)·(I0 + 2Σ(k=1..∞) Ik) = 1. This is O(z) where evaluating each
+// order independently is O(z²), and is stable as the recurrence is dominated by Ik when run backwards.
+// https://en.wikipedia.org/wiki/Bessel_function#Modified_Bessel_functions:_I%CE%B1,_K%CE%B1
+func besselSeriesScaled(z, r float64, from int) float64 {
+	// Start far enough beyond the orders contributing to the series (Ik/I0 ~ exp(-k²/2z) for k < z)
+	n := int(z+20*math.Sqrt(z)) + 40
+	if -from > n {
+		n = -from
+	}
+
+	logR := math.Log(r)
+	next, current := 0.0, 1.0
+	norm, sum := 0.0, 0.0
+
+	for k := n; k >= 0; k-- {
+		if k == 0 {
+			norm += current
+		} else {
+			norm += 2 * current
+		}
+
+		if k >= from {
+			sum += math.Exp(float64(k)*logR) * current
+		}
+		if k > 0 && -k >= from {
+			sum += math.Exp(-float64(k)*logR) * current
+		}
+
+		if k > 0 {
+			next, current = current, 2*float64(k)/z*current+next
+		}
+
+		if current > besselRescale {
+			next, current = next/besselRescale, current/besselRescale
+			norm, sum = norm/besselRescale, sum/besselRescale
+		}
+	}
+
+	return sum / norm
+}
+
+// MaxDopplerFromSpeed calculates the maximum Doppler frequency (v·f/c) in Hz for a receiver moving at the
+// provided speed in km/h
+// https://en.wikipedia.org/wiki/Doppler_effect
+func MaxDopplerFromSpeed(speedKmh float64, freq Frequency) float64 {
+	return speedKmh / 3.6 * float64(freq) / C
+}
+
+// jakesEdgeLimit is the fraction of the maximum Doppler frequency at which the Jakes spectrum is clamped,
+// avoiding the singularity at ±fd
+const jakesEdgeLimit = 0.9999
+
+// JakesDopplerPSD calculates the classic (U-shaped) Jakes Doppler power spectral density at a frequency offset
+// from the carrier for a mobile receiver with uniformly distributed arrival angles, normalised to unit total power.
+// This is zero beyond the maximum Doppler frequency and is clamped approaching ±fd where it is unbounded.
+// https://en.wikipedia.org/wiki/Rayleigh_fading#Doppler_power_spectral_density
+func JakesDopplerPSD(freqOffsetHz, maxDopplerHz float64) float64 {
+	ratio := math.Abs(freqOffsetHz / maxDopplerHz)
+	if ratio > 1 {
+		return 0
+	}
+	ratio = math.Min(ratio, jakesEdgeLimit)
+
+	return 1 / (π * maxDopplerHz * math.Sqrt(1-ratio*ratio))
+}
+
+// rayleighChannelSinusoids is the number of sinusoids summed when simulating Rayleigh channels
+const rayleighChannelSinusoids = 32
+
+// SimulateRayleighChannel simulates a correlated Rayleigh fading envelope for a mobile receiver, returning
+// the fading (relative to the mean received power) for each sample. This uses the Zheng and Xiao
+// sum-of-sinusoids model, with autocorrelation following the Jakes J0(2π·fd·τ) shape.
+// If rng is nil a time seeded source is used.
+// https://en.wikipedia.org/wiki/Rayleigh_fading#Jakes's_model
+func SimulateRayleighChannel(samples int, maxDopplerHz, sampleRateHz float64, rng *rand.Rand) []Attenuation {
+	gains := simulateRayleighChannelGains(samples, maxDopplerHz, sampleRateHz, rng)
+
+	fading := make([]Attenuation, samples)
+	for i, g := range gains {
+		fading[i] = FieldAbsToDB(cmplx.Abs(g))
+	}
+
+	return fading
+}
+
+// simulateRayleighChannelGains generates complex channel gains with unit mean power using the
+// Zheng and Xiao sum-of-sinusoids Rayleigh fading model
+// See: https://doi.org/10.1109/LCOMM.2002.1010874
+func simulateRayleighChannelGains(samples int, maxDopplerHz, sampleRateHz float64, rng *rand.Rand) []complex128 {
+	rng = fadingSource(rng)
+
+	m := rayleighChannelSinusoids
+	ωd := 2 * π * maxDopplerHz
+
+	// Random arrival angles, phases and amplitudes for each sinusoid
+	θ := (rng.Float64()*2 - 1) * π
+	φ := (rng.Float64()*2 - 1) * π
+	frequencies := make([]float64, m)
+	ψ := make([]float64, m)
+	for n := range frequencies {
+		α := (2*π*float64(n+1) - π + θ) / float64(4*m)
+		frequencies[n] = ωd * math.Cos(α)
+		ψ[n] = (rng.Float64()*2 - 1) * π
+	}
+
+	scale := math.Sqrt(2 / float64(m))
+	gains := make([]complex128, samples)
+
+	for i := range gains {
+		t := float64(i) / sampleRateHz
+		re, im := 0.0, 0.0
+		for n := range frequencies {
+			c := math.Cos(frequencies[n]*t + φ)
+			re += math.Cos(ψ[n]) * c
+			im += math.Sin(ψ[n]) * c
+		}
+		gains[i] = complex(scale*re, scale*im)
+	}
+
+	return gains
+}
@@ -0,0 +1,407 @@
+package rf
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFadingDistributions(t *testing.T) {
+
+	t.Run("Can calculate Rayleigh distribution values", func(t *testing.T) {
+		assert.InDelta(t, 0.0, RayleighCDF(0, 1), allowedError)
+		assert.InDelta(t, 0.3935, RayleighCDF(1, 1), 1e-4)
+		assert.InDelta(t, 0.5, RayleighCDF(math.Sqrt(2*math.Ln2), 1), 1e-9)
+		assert.InDelta(t, 0.6065, RayleighPDF(1, 1), 1e-4)
+		assert.InDelta(t, 0.0, RayleighPDF(-1, 1), 1e-9)
+
+		assert.InDelta(t, RayleighCDF(2, 1.5), Integrate(func(x float64) float64 { return RayleighPDF(x, 1.5) }, 0, 2, 10000), 1e-6)
+	})
+
+	t.Run("Can calculate Weibull distribution values", func(t *testing.T) {
+		// Shape 1 is the exponential distribution
+		assert.InDelta(t, 1-math.Exp(-1), WeibullCDF(1, 1, 1), 1e-9)
+		assert.InDelta(t, math.Exp(-1), WeibullPDF(1, 1, 1), 1e-9)
+
+		// Shape 2 is the Rayleigh distribution with scale √2σ
+		for _, x := range []float64{0.1, 0.5, 1.0, 2.0, 4.0} {
+			assert.InDelta(t, RayleighCDF(x, 1.5), WeibullCDF(x, 2, math.Sqrt2*1.5), 1e-9)
+			assert.InDelta(t, RayleighPDF(x, 1.5), WeibullPDF(x, 2, math.Sqrt2*1.5), 1e-9)
+		}
+
+		assert.InDelta(t, WeibullCDF(3, 3.2, 1.5), Integrate(func(x float64) float64 { return WeibullPDF(x, 3.2, 1.5) }, 0, 3, 10000), 1e-6)
+	})
+
+	t.Run("Can calculate Rician distribution values", func(t *testing.T) {
+		// No line of sight component reduces to Rayleigh
+		for _, x := range []float64{0.1, 0.5, 1.0, 2.0, 4.0} {
+			assert.InDelta(t, RayleighCDF(x, 1.5), RicianCDF(x, 0, 1.5), 1e-9)
+			assert.InDelta(t, RayleighPDF(x, 1.5), RicianPDF(x, 0, 1.5), 1e-9)
+		}
+
+		// Known value Q1(1, 1) = 0.7328798
+		assert.InDelta(t, 1-0.7328798, RicianCDF(1, 1, 1), 1e-6)
+
+		// CDF is consistent with the integrated PDF, including for large K factors
+		for _, v := range []float64{0.5, 2.0, 10.0, 40.0} {
+			x := v + 1
+			expected := Integrate(func(x float64) float64 { return RicianPDF(x, v, 1) }, 0, x, 20000)
+			assert.InDelta(t, expected, RicianCDF(x, v, 1), 1e-6, "v: %f", v)
+		}
+	})
+}
+
+func TestFadingGenerators(t *testing.T) {
+
+	const samples = 100000
+
+	// checkAgainstCDF compares the sampled probability of fades of at least each depth with the distribution CDF
+	checkAgainstCDF := func(t *testing.T, generate func(rng *rand.Rand) (Attenuation, error), cdf func(x float64) float64) {
+		rng := rand.New(rand.NewSource(1))
+		fades := make([]Attenuation, samples)
+		for i := range fades {
+			fade, err := generate(rng)
+			assert.Nil(t, err)
+			fades[i] = fade
+		}
+
+		for _, depth := range []float64{-6, -3, 0, 3, 10, 20} {
+			count := 0
+			for _, fade := range fades {
+				if float64(fade) >= depth {
+					count++
+				}
+			}
+			assert.InDelta(t, cdf(math.Pow(10, -depth/20)), float64(count)/samples, 0.005, "fade depth %.0fdB", depth)
+		}
+	}
+
+	t.Run("Rayleigh fading matches the Rayleigh CDF", func(t *testing.T) {
+		checkAgainstCDF(t, func(rng *rand.Rand) (Attenuation, error) {
+			return CalculateRaleighFading(1/math.Sqrt2, rng)
+		}, func(x float64) float64 { return RayleighCDF(x, 1/math.Sqrt2) })
+	})
+
+	t.Run("Rician fading matches the Rician CDF", func(t *testing.T) {
+		checkAgainstCDF(t, func(rng *rand.Rand) (Attenuation, error) {
+			return CalculateRicanFading(1, 0.5, rng)
+		}, func(x float64) float64 { return RicianCDF(x, 1, 0.5) })
+	})
+
+	t.Run("Weibull fading matches the Weibull CDF", func(t *testing.T) {
+		checkAgainstCDF(t, func(rng *rand.Rand) (Attenuation, error) {
+			return CalculateWeibullFading(1.5, 1, rng)
+		}, func(x float64) float64 { return WeibullCDF(x, 1.5, 1) })
+	})
+
+	t.Run("Rejects invalid fading parameters", func(t *testing.T) {
+		_, err := CalculateRaleighFading(0, nil)
+		assert.NotNil(t, err)
+		_, err = CalculateRicanFading(-1, 1, nil)
+		assert.NotNil(t, err)
+		_, err = CalculateWeibullFading(0, 1, nil)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestFadeMargin(t *testing.T) {
+
+	t.Run("Can calculate Rayleigh fade margin", func(t *testing.T) {
+		margin, err := FadeMarginForOutage(FadeRayleigh, 0.01)
+		assert.Nil(t, err)
+		assert.InDelta(t, 19.98, float64(margin), 0.01)
+
+		// Outage matches the Rayleigh distribution with unit mean power
+		threshold := math.Pow(10, -float64(margin)/20)
+		assert.InDelta(t, 0.01, RayleighCDF(threshold, math.Sqrt(0.5)), 1e-9)
+	})
+
+	t.Run("Can calculate log-normal fade margin", func(t *testing.T) {
+		margin, err := FadeMarginForOutage(FadeLogNormal, 0.01, 8)
+		assert.Nil(t, err)
+		assert.InDelta(t, 2.3263*8, float64(margin), 0.001)
+
+		assert.InDelta(t, 0.01, QFunction(float64(margin)/8), 1e-9)
+	})
+
+	t.Run("Can calculate Rician fade margin", func(t *testing.T) {
+		rayleigh, _ := FadeMarginForOutage(FadeRayleigh, 0.01)
+
+		// Weak line of sight approaches Rayleigh fading
+		weak, err := FadeMarginForOutage(FadeRician, 0.01, -30)
+		assert.Nil(t, err)
+		assert.InDelta(t, float64(rayleigh), float64(weak), 0.01)
+
+		// Stronger line of sight requires less margin
+		previous := weak
+		for _, k := range []float64{0, 6, 10, 20} {
+			margin, err := FadeMarginForOutage(FadeRician, 0.01, k)
+			assert.Nil(t, err)
+			assert.True(t, margin < previous, "K %.0fdB", k)
+			previous = margin
+		}
+	})
+
+	t.Run("Rician fade margin is fast for strong line of sight", func(t *testing.T) {
+		start := time.Now()
+
+		for _, kdB := range []float64{30, 40, 50} {
+			margin, err := FadeMarginForOutage(FadeRician, 0.01, kdB)
+			assert.Nil(t, err)
+
+			// The envelope approaches a gaussian about the line of sight amplitude as K increases
+			k := math.Pow(10, kdB/10)
+			v, sigma := math.Sqrt(k/(k+1)), math.Sqrt(1/(2*(k+1)))
+			x := v - sigma*math.Sqrt2*math.Erfinv(1-2*0.01)
+			assert.InDelta(t, -20*math.Log10(x), float64(margin), 0.01, "K %.0fdB", kdB)
+		}
+
+		assert.True(t, time.Since(start) < 5*time.Second, "took %s", time.Since(start))
+	})
+
+	t.Run("Fade margin uses the provided solver options", func(t *testing.T) {
+		fine, _ := FadeMarginForOutage(FadeRician, 0.01, 6)
+
+		coarse, err := FadeMarginForOutageWithOptions(FadeRician, 0.01, SolverOptions{MaxIterations: 100, Tolerance: 1e-3}, 6)
+		assert.Nil(t, err)
+		assert.InDelta(t, float64(fine), float64(coarse), 0.1)
+
+		_, err = FadeMarginForOutageWithOptions(FadeRician, 0.01, SolverOptions{}, 6)
+		assert.NotNil(t, err)
+
+		_, err = DiversityGainWithOptions(2, DiversityMRC, 0, SolverOptions{})
+		assert.NotNil(t, err)
+
+		// Non-convergence is reported rather than returning NaN
+		_, err = DiversityGainWithOptions(2, DiversityMRC, 0, SolverOptions{MaxIterations: 1, Tolerance: 1e-15})
+		assert.NotNil(t, err)
+		_, err = FadeMarginForOutageWithOptions(FadeRician, 0.01, SolverOptions{MaxIterations: 1, Tolerance: 1e-15}, 6)
+		assert.Contains(t, err.Error(), "did not converge")
+	})
+
+	t.Run("Diversity gain with options rejects invalid parameters", func(t *testing.T) {
+		_, err := DiversityGainWithOptions(0, DiversityMRC, 0, DefaultSolverOptions)
+		assert.NotNil(t, err)
+		_, err = DiversityGainWithOptions(2, DiversityMRC, 1.5, DefaultSolverOptions)
+		assert.NotNil(t, err)
+		_, err = DiversityGainWithOptions(2, "unknown", 0, DefaultSolverOptions)
+		assert.NotNil(t, err)
+
+		gain, err := DiversityGainWithOptions(2, DiversityMRC, 0, DefaultSolverOptions)
+		assert.Nil(t, err)
+		assert.InDelta(t, float64(DiversityGain(2, DiversityMRC, 0)), float64(gain), allowedError)
+	})
+
+	t.Run("Rejects invalid fade margin parameters", func(t *testing.T) {
+		_, err := FadeMarginForOutage(FadeRayleigh, 0)
+		assert.NotNil(t, err)
+		_, err = FadeMarginForOutage(FadeRayleigh, 1)
+		assert.NotNil(t, err)
+		_, err = FadeMarginForOutage(FadeLogNormal, 0.01)
+		assert.NotNil(t, err)
+		_, err = FadeMarginForOutage(FadeRician, 0.01)
+		assert.NotNil(t, err)
+		_, err = FadeMarginForOutage("nakagami", 0.01)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestDiversityGain(t *testing.T) {
+
+	t.Run("Can calculate dual branch diversity gain", func(t *testing.T) {
+		// Selection threshold -ln(1 - √0.01) relative to the single branch -ln(0.99)
+		assert.InDelta(t, 10*math.Log10(math.Log(0.9)/math.Log(0.99)), float64(DiversityGain(2, DiversitySelection, 0)), 1e-9)
+		assert.InDelta(t, 11.7, float64(DiversityGain(2, DiversityMRC, 0)), 0.05)
+
+		// A single branch has no gain
+		for _, technique := range []DiversityTechnique{DiversitySelection, DiversityMRC, DiversityEGC} {
+			assert.InDelta(t, 0.0, float64(DiversityGain(1, technique, 0)), 1e-6, "%s", technique)
+		}
+	})
+
+	t.Run("MRC outperforms EGC and selection combining", func(t *testing.T) {
+		for _, branches := range []int{2, 3, 4, 8} {
+			selection := DiversityGain(branches, DiversitySelection, 0)
+			mrc := DiversityGain(branches, DiversityMRC, 0)
+			egc := DiversityGain(branches, DiversityEGC, 0)
+
+			assert.True(t, mrc > egc, "branches %d", branches)
+			assert.True(t, egc > selection, "branches %d", branches)
+		}
+	})
+
+	t.Run("Gain increases with branches", func(t *testing.T) {
+		previous := Attenuation(0)
+		for _, branches := range []int{2, 3, 4, 8} {
+			gain := DiversityGain(branches, DiversityMRC, 0)
+			assert.True(t, gain > previous, "branches %d", branches)
+			previous = gain
+		}
+	})
+
+	t.Run("Can calculate gain for large branch counts", func(t *testing.T) {
+		previous := DiversityGain(100, DiversityMRC, 0)
+		for _, branches := range []int{200, 500, 1000} {
+			gain := DiversityGain(branches, DiversityMRC, 0)
+			assert.False(t, math.IsNaN(float64(gain)), "branches %d", branches)
+			assert.True(t, gain > previous, "branches %d", branches)
+			previous = gain
+		}
+	})
+
+	t.Run("Correlation reduces gain", func(t *testing.T) {
+		previous := DiversityGain(2, DiversityMRC, 0)
+		for _, ρ := range []float64{0.3, 0.7, 0.9} {
+			gain := DiversityGain(2, DiversityMRC, ρ)
+			assert.True(t, gain < previous, "correlation %.1f", ρ)
+			previous = gain
+		}
+		assert.InDelta(t, 0.0, float64(DiversityGain(2, DiversityMRC, 1)), 1e-9)
+	})
+
+	t.Run("Rejects invalid diversity parameters", func(t *testing.T) {
+		assert.True(t, math.IsNaN(float64(DiversityGain(0, DiversityMRC, 0))))
+		assert.True(t, math.IsNaN(float64(DiversityGain(2, DiversityMRC, 1.5))))
+		assert.True(t, math.IsNaN(float64(DiversityGain(2, "switched", 0))))
+	})
+}
+
+func TestMarcumQ(t *testing.T) {
+
+	t.Run("Matches tabulated values", func(t *testing.T) {
+		tests := []struct {
+			m    int
+			a, b float64
+			q    float64
+		}{
+			{1, 1, 1, 0.7328798},
+			{1, 1, 2, 0.2690121},
+			{1, 2, 1, 0.9181077},
+			{1, 0.5, 3, 0.0178437},
+			{2, 1, 2, 0.5301469},
+			{2, 2, 1, 0.9833914},
+			{3, 1, 3, 0.2597819},
+		}
+
+		for _, test := range tests {
+			assert.InDelta(t, test.q, MarcumQ(test.m, test.a, test.b), 1e-6, "Q%d(%.1f, %.1f)", test.m, test.a, test.b)
+		}
+	})
+
+	t.Run("Reduces to the chi distribution without line of sight", func(t *testing.T) {
+		b := 1.5
+		assert.InDelta(t, math.Exp(-b*b/2), MarcumQ(1, 0, b), 1e-12)
+		assert.InDelta(t, math.Exp(-b*b/2)*(1+b*b/2), MarcumQ(2, 0, b), 1e-12)
+		assert.InDelta(t, MarcumQ(2, 1e-9, b), MarcumQ(2, 0, b), 1e-6)
+	})
+
+	t.Run("Handles edge cases", func(t *testing.T) {
+		assert.Equal(t, 1.0, MarcumQ(2, 1, 0))
+		assert.True(t, math.IsNaN(MarcumQ(0, 1, 1)))
+
+		// Increasing order increases the tail probability
+		assert.True(t, MarcumQ(2, 1, 2) > MarcumQ(1, 1, 2))
+	})
+
+	t.Run("Approaches the gaussian tail for large arguments", func(t *testing.T) {
+		for _, a := range []float64{100, 1000} {
+			for _, offset := range []float64{-2, 0, 1, 3} {
+				expected := 0.5 * math.Erfc(offset/math.Sqrt2)
+				assert.InDelta(t, expected, MarcumQ(1, a, a+offset), 0.01, "Q1(%.0f, %.0f)", a, a+offset)
+			}
+		}
+	})
+}
+
+func TestFadingChannelSimulation(t *testing.T) {
+
+	fd, fs := 100.0, 10000.0
+
+	t.Run("Simulated Rayleigh channels have unit mean power", func(t *testing.T) {
+		fading := SimulateRayleighChannel(100000, fd, fs, rand.New(rand.NewSource(1)))
+		assert.Len(t, fading, 100000)
+
+		power := 0.0
+		for _, f := range fading {
+			power += math.Pow(f.FieldDBToAbs(), 2)
+		}
+		assert.InDelta(t, 1.0, power/float64(len(fading)), 0.2)
+	})
+
+	t.Run("Simulated Rayleigh channel autocorrelation follows Jakes model", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1))
+		realisations, samples, window := 500, 400, 300
+		lags := []int{0, 10, 20, 38, 50, 80}
+
+		// Average over both realisations and time offsets within each realisation
+		correlation := make([]float64, len(lags))
+		for r := 0; r < realisations; r++ {
+			gains := simulateRayleighChannelGains(samples, fd, fs, rng)
+			for i, lag := range lags {
+				for j := 0; j < window; j++ {
+					correlation[i] += real(gains[j]*cmplx.Conj(gains[j+lag])) / float64(realisations*window)
+				}
+			}
+		}
+
+		for i, lag := range lags {
+			τ := float64(lag) / fs
+			assert.InDelta(t, math.J0(2*math.Pi*fd*τ), correlation[i], 0.05, "lag %d", lag)
+		}
+	})
+}
+
+func TestDoppler(t *testing.T) {
+
+	t.Run("Can calculate maximum Doppler for vehicle speeds", func(t *testing.T) {
+		tests := []struct {
+			speed   float64
+			f       Frequency
+			doppler float64
+		}{
+			{100, 900 * MHz, 83.4},
+			{120, 1.9 * GHz, 211.2},
+			{50, 2.4 * GHz, 111.2},
+			{0, 900 * MHz, 0},
+		}
+
+		for _, test := range tests {
+			assert.InDelta(t, test.doppler, MaxDopplerFromSpeed(test.speed, test.f), 0.1, "%.0fkm/h at %.0fMHz", test.speed, test.f/MHz)
+		}
+	})
+
+	t.Run("Jakes spectrum is minimum at the carrier", func(t *testing.T) {
+		fd := 100.0
+		centre := JakesDopplerPSD(0, fd)
+		assert.InDelta(t, 1/(math.Pi*fd), centre, 1e-9)
+
+		for _, f := range []float64{-90, -50, 20, 50, 90} {
+			assert.True(t, JakesDopplerPSD(f, fd) > centre, "offset %.0f", f)
+			assert.InDelta(t, JakesDopplerPSD(-f, fd), JakesDopplerPSD(f, fd), 1e-12)
+		}
+	})
+
+	t.Run("Jakes spectrum peaks at the Doppler limits", func(t *testing.T) {
+		fd := 100.0
+		edge := JakesDopplerPSD(fd, fd)
+		assert.False(t, math.IsInf(edge, 0))
+		assert.True(t, edge > 10*JakesDopplerPSD(0, fd))
+		assert.InDelta(t, edge, JakesDopplerPSD(-fd, fd), 1e-12)
+
+		assert.Equal(t, 0.0, JakesDopplerPSD(100.1, fd))
+		assert.Equal(t, 0.0, JakesDopplerPSD(-150, fd))
+	})
+
+	t.Run("Jakes spectrum has unit power", func(t *testing.T) {
+		fd := 100.0
+		// Substitute f = fd·sin(θ) to remove the edge singularities, clamping loses a little power at the edges
+		power := Integrate(func(θ float64) float64 {
+			return JakesDopplerPSD(fd*math.Sin(θ), fd) * fd * math.Cos(θ)
+		}, -math.Pi/2, math.Pi/2, 1000)
+		assert.InDelta(t, 1.0, power, 0.01)
+	})
+}
@@ -0,0 +1,181 @@
+package rf
+
+import (
+	"fmt"
+	"math"
+)
+
+// Geodetic helpers
+
+// Coordinate is a latitude and longitude in degrees
+type Coordinate struct {
+	Lat, Lon float64
+}
+
+// WGS-84 ellipsoid parameters
+// See: https://en.wikipedia.org/wiki/World_Geodetic_System#WGS84
+const (
+	// WGS84A is the WGS-84 semi-major (equatorial) axis in meters
+	WGS84A = 6378137.0
+	// WGS84F is the WGS-84 flattening
+	WGS84F = 1 / 298.257223563
+	// WGS84B is the WGS-84 semi-minor (polar) axis in meters
+	WGS84B = WGS84A * (1 - WGS84F)
+	// WGS84E2 is the WGS-84 first eccentricity squared
+	WGS84E2 = WGS84F * (2 - WGS84F)
+)
+
+// LatLonAltToECEF converts a geodetic latitude, longitude (degrees) and altitude (m)
+// to Earth Centered Earth Fixed (ECEF) coordinates in meters
+// See: https://en.wikipedia.org/wiki/Geographic_coordinate_conversion#From_geodetic_to_ECEF_coordinates
+func LatLonAltToECEF(lat, lng, alt float64) (x, y, z float64) {
+	φ, λ := lat/180*π, lng/180*π
+
+	// Prime vertical radius of curvature
+	n := WGS84A / math.Sqrt(1-WGS84E2*math.Pow(math.Sin(φ), 2))
+
+	x = (n + alt) * math.Cos(φ) * math.Cos(λ)
+	y = (n + alt) * math.Cos(φ) * math.Sin(λ)
+	z = (n*(1-WGS84E2) + alt) * math.Sin(φ)
+
+	return x, y, z
+}
+
+// ECEFToENU rotates an ECEF offset (dx, dy, dz) into the local East North Up frame
+// of an observer at the provided latitude and longitude (degrees)
+// See: https://en.wikipedia.org/wiki/Geographic_coordinate_conversion#From_ECEF_to_ENU
+func ECEFToENU(lat, lng, dx, dy, dz float64) (e, n, u float64) {
+	φ, λ := lat/180*π, lng/180*π
+
+	e = -math.Sin(λ)*dx + math.Cos(λ)*dy
+	n = -math.Sin(φ)*math.Cos(λ)*dx - math.Sin(φ)*math.Sin(λ)*dy + math.Cos(φ)*dz
+	u = math.Cos(φ)*math.Cos(λ)*dx + math.Cos(φ)*math.Sin(λ)*dy + math.Sin(φ)*dz
+
+	return e, n, u
+}
+
+// LookAngles calculates the azimuth (degrees clockwise from true north), elevation (degrees above
+// the local horizon) and slant range from an observer to a target, for example to point a steerable
+// antenna at an aircraft or satellite. Positions are geodetic latitude and longitude in degrees
+// with altitudes in meters above the WGS-84 ellipsoid.
+func LookAngles(observerLat, observerLon, observerAlt, targetLat, targetLon, targetAlt float64) (azimuthDeg, elevationDeg float64, slant Distance) {
+	x1, y1, z1 := LatLonAltToECEF(observerLat, observerLon, observerAlt)
+	x2, y2, z2 := LatLonAltToECEF(targetLat, targetLon, targetAlt)
+
+	e, n, u := ECEFToENU(observerLat, observerLon, x2-x1, y2-y1, z2-z1)
+
+	horizontal := math.Sqrt(e*e + n*n)
+
+	azimuthDeg = math.Atan2(e, n) * 180 / π
+	if azimuthDeg < 0 {
+		azimuthDeg += 360
+	}
+	elevationDeg = math.Atan2(u, horizontal) * 180 / π
+	slant = Distance(math.Sqrt(e*e + n*n + u*u))
+
+	return azimuthDeg, elevationDeg, slant
+}
+
+// EarthRadiusAtLatitude calculates the WGS-84 Gaussian (geometric mean) radius of curvature in meters at a
+// latitude in degrees, for use as a local earth radius with CalculateDistance
+// See: https://en.wikipedia.org/wiki/Earth_radius#Gaussian_radius
+func EarthRadiusAtLatitude(lat float64) float64 {
+	s := math.Sin(lat / 180 * π)
+	w := 1 - WGS84E2*s*s
+
+	// Meridional (M) and prime vertical (N) radii of curvature
+	m := WGS84A * (1 - WGS84E2) / math.Pow(w, 1.5)
+	n := WGS84A / math.Sqrt(w)
+
+	return math.Sqrt(m * n)
+}
+
+// Maidenhead locator pair sizes in degrees of longitude (latitude pairs are half the size)
+// for fields, squares, subsquares and extended squares
+var maidenheadLonSizes = []float64{20, 2, 2.0 / 24, 2.0 / 240}
+
+// maidenheadBases are the number of symbols in each locator pair
+var maidenheadBases = []int{18, 10, 24, 10}
+
+// GridSquareToLatLon converts a Maidenhead grid locator (2, 4, 6 or 8 characters, ie. "RF73lb") to the
+// latitude and longitude in degrees of the centre of the grid square
+// https://en.wikipedia.org/wiki/Maidenhead_Locator_System
+func GridSquareToLatLon(grid string) (lat, lon float64, err error) {
+	if len(grid) < 2 || len(grid) > 8 || len(grid)%2 != 0 {
+		return 0, 0, fmt.Errorf("Grid square must have 2, 4, 6 or 8 characters (grid: %s)", grid)
+	}
+
+	lon, lat = -180, -90
+	for pair := 0; pair < len(grid)/2; pair++ {
+		size := maidenheadLonSizes[pair]
+
+		lonIndex, err := maidenheadIndex(grid[pair*2], pair)
+		if err != nil {
+			return 0, 0, fmt.Errorf("Invalid grid square %s: %s", grid, err)
+		}
+		latIndex, err := maidenheadIndex(grid[pair*2+1], pair)
+		if err != nil {
+			return 0, 0, fmt.Errorf("Invalid grid square %s: %s", grid, err)
+		}
+
+		lon += float64(lonIndex) * size
+		lat += float64(latIndex) * size / 2
+	}
+
+	// Offset to the centre of the final square
+	size := maidenheadLonSizes[len(grid)/2-1]
+	return lat + size/4, lon + size/2, nil
+}
+
+// maidenheadIndex decodes a single Maidenhead locator character for the provided pair
+func maidenheadIndex(c byte, pair int) (int, error) {
+	index := -1
+	switch {
+	case pair%2 == 1 && c >= '0' && c <= '9':
+		index = int(c - '0')
+	case pair%2 == 0 && c >= 'A' && c <= 'Z':
+		index = int(c - 'A')
+	case pair%2 == 0 && c >= 'a' && c <= 'z':
+		index = int(c - 'a')
+	}
+
+	if index < 0 || index >= maidenheadBases[pair] {
+		return 0, fmt.Errorf("invalid character '%c' at position %d", c, pair*2)
+	}
+	return index, nil
+}
+
+// LatLonToGridSquare converts a latitude and longitude in degrees to a Maidenhead grid locator with the
+// provided precision in characters (2, 4, 6 or 8, clamped to this range and rounded down to an even number)
+// https://en.wikipedia.org/wiki/Maidenhead_Locator_System
+func LatLonToGridSquare(lat, lon float64, precision int) string {
+	pairs := int(math.Max(1, math.Min(4, float64(precision/2))))
+
+	// Offset from the south west corner, clamping the poles and antimeridian into the final squares
+	x := math.Mod(lon+180, 360)
+	if x < 0 {
+		x += 360
+	}
+	y := math.Max(0, math.Min(lat+90, 180-1e-9))
+
+	grid := make([]byte, 0, pairs*2)
+	for pair := 0; pair < pairs; pair++ {
+		size := maidenheadLonSizes[pair]
+
+		lonIndex := int(math.Floor(x / size))
+		latIndex := int(math.Floor(y / (size / 2)))
+		x -= float64(lonIndex) * size
+		y -= float64(latIndex) * size / 2
+
+		switch pair {
+		case 0:
+			grid = append(grid, byte('A'+lonIndex), byte('A'+latIndex))
+		case 2:
+			grid = append(grid, byte('a'+lonIndex), byte('a'+latIndex))
+		default:
+			grid = append(grid, byte('0'+lonIndex), byte('0'+latIndex))
+		}
+	}
+
+	return string(grid)
+}
@@ -0,0 +1,104 @@
+package rf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeo(t *testing.T) {
+
+	t.Run("Can convert geodetic coordinates to ECEF", func(t *testing.T) {
+		x, y, z := LatLonAltToECEF(0, 0, 0)
+		assert.InDelta(t, WGS84A, x, allowedError)
+		assert.InDelta(t, 0.0, y, allowedError)
+		assert.InDelta(t, 0.0, z, allowedError)
+
+		x, y, z = LatLonAltToECEF(90, 0, 0)
+		assert.InDelta(t, 0.0, x, allowedError)
+		assert.InDelta(t, 0.0, y, allowedError)
+		assert.InDelta(t, WGS84B, z, allowedError)
+	})
+
+	t.Run("Can calculate look angles to a target due north", func(t *testing.T) {
+		az, el, slant := LookAngles(0, 0, 0, 0.1, 0, 0)
+
+		// Target on the ellipsoid surface sits slightly below the local horizon
+		assert.InDelta(t, 0.0, az, allowedError)
+		assert.InDelta(t, -0.05, el, 0.001)
+		assert.InDelta(t, 11057.4, float64(slant), 1)
+	})
+
+	t.Run("Can calculate look angles to a target directly overhead", func(t *testing.T) {
+		_, el, slant := LookAngles(-36.8485, 174.7633, 10, -36.8485, 174.7633, 1010)
+
+		assert.InDelta(t, 90.0, el, allowedError)
+		assert.InDelta(t, 1000.0, float64(slant), allowedError)
+	})
+
+	t.Run("Can calculate the earth radius at a latitude", func(t *testing.T) {
+		// Equatorial Gaussian radius is the semi-minor axis, polar is a²/b
+		assert.InDelta(t, 6356752.314, EarthRadiusAtLatitude(0), 0.001)
+		assert.InDelta(t, 6399593.626, EarthRadiusAtLatitude(90), 0.001)
+		assert.InDelta(t, 6399593.626, EarthRadiusAtLatitude(-90), 0.001)
+
+		// Mid-latitudes are close to the mean earth radius
+		assert.InDelta(t, R, EarthRadiusAtLatitude(45), 10e+3)
+	})
+
+	t.Run("Local earth radius changes great circle distances", func(t *testing.T) {
+		d := CalculateDistance(0, 0, 1, 0, EarthRadiusAtLatitude(0.5))
+		assert.InDelta(t, 110.95e+3, float64(d), 0.1e+3)
+	})
+
+	t.Run("Can convert lat/lon to grid squares", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			lat, lon float64
+			grid     string
+		}{
+			{"Auckland", -36.8485, 174.7633, "RF73jd"},
+			{"Munich", 48.1464, 11.6081, "JN58td"},
+			{"Newington", 41.7147, -72.7272, "FN31pr"},
+			{"Mt Kilimanjaro", -3.0758, 37.3533, "KI86qw"},
+		}
+
+		for _, test := range tests {
+			assert.Equal(t, test.grid, LatLonToGridSquare(test.lat, test.lon, 6), test.name)
+			assert.Equal(t, test.grid[:4], LatLonToGridSquare(test.lat, test.lon, 4), test.name)
+			assert.Equal(t, test.grid[:2], LatLonToGridSquare(test.lat, test.lon, 2), test.name)
+		}
+	})
+
+	t.Run("Can convert grid squares to lat/lon", func(t *testing.T) {
+		lat, lon, err := GridSquareToLatLon("JN58td")
+		assert.Nil(t, err)
+		assert.InDelta(t, 48.1458, lat, 0.0001)
+		assert.InDelta(t, 11.625, lon, 0.0001)
+
+		lat, lon, err = GridSquareToLatLon("jn58")
+		assert.Nil(t, err)
+		assert.InDelta(t, 48.5, lat, allowedError)
+		assert.InDelta(t, 11.0, lon, allowedError)
+
+		lat, lon, err = GridSquareToLatLon("AA")
+		assert.Nil(t, err)
+		assert.InDelta(t, -85.0, lat, allowedError)
+		assert.InDelta(t, -170.0, lon, allowedError)
+	})
+
+	t.Run("Grid squares round trip at each precision", func(t *testing.T) {
+		for _, grid := range []string{"RF", "RF73", "RF73jd", "RF73jd45", "JN58td", "FN31pr", "AA00aa00", "RR99xx99"} {
+			lat, lon, err := GridSquareToLatLon(grid)
+			assert.Nil(t, err, grid)
+			assert.Equal(t, grid, LatLonToGridSquare(lat, lon, len(grid)), grid)
+		}
+	})
+
+	t.Run("Rejects invalid grid squares", func(t *testing.T) {
+		for _, grid := range []string{"", "R", "RF7", "SF73", "RFA3", "RF73yc", "RF73jdA0", "RF73jd45aa"} {
+			_, _, err := GridSquareToLatLon(grid)
+			assert.NotNil(t, err, grid)
+		}
+	})
+}
@@ -2,9 +2,12 @@ package rf
 
 import (
 	"bytes"
+	"encoding/csv"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math"
+	"strconv"
 
 	"github.com/wcharczuk/go-chart"
 )
@@ -16,11 +19,77 @@ func FrequencyToWavelength(freq Frequency) Wavelength {
 	return Wavelength(C / freq)
 }
 
+// FrequencyToWavelengthChecked calculates a wavelength from a frequency, returning an error
+// for zero or negative frequencies rather than an infinite or negative wavelength
+func FrequencyToWavelengthChecked(freq Frequency) (Wavelength, error) {
+	if freq <= 0 {
+		return 0, fmt.Errorf("Frequency must be positive to calculate a wavelength (frequency: %.2fHz)", freq)
+	}
+	return FrequencyToWavelength(freq), nil
+}
+
 // WavelengthToFrequency calculates a frequency from a wavelength
 func WavelengthToFrequency(wavelength Wavelength) Frequency {
 	return Frequency(C / wavelength)
 }
 
+// Propagation medium helpers
+// See: https://en.wikipedia.org/wiki/Velocity_factor
+
+// C0 is the speed of light in a vacuum in meters per second
+const C0 = 2.99792458e+8
+
+// Medium describes a propagation medium by its velocity factor relative to the speed of light in a vacuum
+type Medium struct {
+	VelocityFactor float64
+}
+
+// Common propagation media, air is consistent with C used by the rest of the package
+var (
+	Vacuum = Medium{VelocityFactor: 1.0}
+	Air    = Medium{VelocityFactor: C / C0}
+)
+
+// Velocity calculates the propagation speed in the medium in meters per second
+func (m Medium) Velocity() float64 {
+	return C0 * m.VelocityFactor
+}
+
+// FrequencyToWavelengthInMedium calculates a wavelength from a frequency in the provided medium
+func FrequencyToWavelengthInMedium(freq Frequency, medium Medium) Wavelength {
+	return Wavelength(medium.Velocity() / float64(freq))
+}
+
+// WavelengthToFrequencyInMedium calculates a frequency from a wavelength in the provided medium
+func WavelengthToFrequencyInMedium(wavelength Wavelength, medium Medium) Frequency {
+	return Frequency(medium.Velocity() / float64(wavelength))
+}
+
+// HeightInWavelengths converts a height in meters to a number of wavelengths at the provided frequency
+func HeightInWavelengths(heightM float64, freq Frequency) float64 {
+	return heightM / float64(FrequencyToWavelength(freq))
+}
+
+// PropagationDelay calculates the one way propagation delay in seconds over a distance in free space
+func PropagationDelay(distance Distance) float64 {
+	return float64(distance) / C
+}
+
+// RoundTripDelay calculates the round trip propagation delay in seconds over a distance in free space
+func RoundTripDelay(distance Distance) float64 {
+	return 2 * PropagationDelay(distance)
+}
+
+// PhaseShift calculates the phase in radians accumulated by a wave propagating over a distance in free space (2π·d/λ)
+func PhaseShift(distance Distance, freq Frequency) float64 {
+	return 2 * π * float64(distance) / float64(FrequencyToWavelength(freq))
+}
+
+// PhaseDifference calculates the difference in radians between the phase accumulated over two paths (d1 - d2)
+func PhaseDifference(d1, d2 Distance, freq Frequency) float64 {
+	return PhaseShift(d1, freq) - PhaseShift(d2, freq)
+}
+
 // Power Decibel helpers
 // See https://en.wikipedia.org/wiki/Decibel#Power_quantities
 
@@ -36,6 +105,15 @@ func MilliWattToDecibelMilliVolt(mw float64) float64 {
 	return 10 * math.Log10(mw)
 }
 
+// AddDecibels sums power quantities expressed in decibels (ie. dBm), returning the total in the same units
+func AddDecibels(values ...float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += math.Pow(10, v/10)
+	}
+	return 10 * math.Log10(sum)
+}
+
 // Distance and Radius calculations
 
 // CalculateDistance calculates the distance between two latitude and longitudes
@@ -54,6 +132,22 @@ func CalculateDistance(lat1, lng1, lat2, lng2, radius float64) Distance {
 	return Distance(d)
 }
 
+// CalculateBearing calculates the initial bearing (degrees clockwise from true north) on the great circle
+// path from the first to the second latitude and longitude
+// See: http://www.movable-type.co.uk/scripts/latlong.html
+func CalculateBearing(lat1, lng1, lat2, lng2 float64) float64 {
+
+	φ1, λ1 := lat1/180*π, lng1/180*π
+	φ2, λ2 := lat2/180*π, lng2/180*π
+	Δλ := λ2 - λ1
+
+	y := math.Sin(Δλ) * math.Cos(φ2)
+	x := math.Cos(φ1)*math.Sin(φ2) - math.Sin(φ1)*math.Cos(φ2)*math.Cos(Δλ)
+	θ := math.Atan2(y, x)
+
+	return math.Mod(θ*180/π+360, 360)
+}
+
 // CalculateDistanceLOS calculates the approximate Line of Sight distance between two lat/lon/alt points
 // This achieves this by wrapping the haversine formula with a flat-earth approximation for height
 // difference. This will be very inaccurate with larger distances.
@@ -128,21 +222,68 @@ func TerrainToPath(p1, p2 float64, d Distance, terrain []float64) (Δd, Δh, θ
 	return Δd, Δh, θ, diffs
 }
 
+// ValidateProfileLength checks that a claimed terrain profile length matches the great circle distance between
+// the profile endpoints (using the mean earth radius) to within the provided fractional tolerance (ie. 0.01 for 1%)
+func ValidateProfileLength(lat1, lon1, lat2, lon2 float64, claimedLength Distance, tolerance float64) error {
+	d := CalculateDistance(lat1, lon1, lat2, lon2, R)
+
+	if math.Abs(float64(claimedLength-d)) > tolerance*float64(d) {
+		return fmt.Errorf("Profile length %.2fm does not match great circle distance %.2fm (tolerance: %.2f%%)", claimedLength, d, tolerance*100)
+	}
+
+	return nil
+}
+
 // TerrainToPathXY Converts terrain between two points of set heights into distances from the path between those points
+// Terrain with fewer than two points cannot be placed along the path, so empty slices are returned
 func TerrainToPathXY(p1, p2 float64, d Distance, terrain []float64) (x, y []float64, d2 float64) {
-	height := (p2 - p1)
-	θ := math.Atan2(height, float64(d))
-	d2 = math.Sqrt(math.Pow(float64(d), 2) + math.Pow(height, 2))
+	if len(terrain) < 2 {
+		return []float64{}, []float64{}, math.Sqrt(math.Pow(float64(d), 2) + math.Pow(p2-p1, 2))
+	}
 
-	Δh := height / float64(len(terrain)-1)
 	Δd := float64(d) / float64(len(terrain)-1)
 
+	distances := make([]float64, len(terrain))
+	for i := range distances {
+		distances[i] = Δd * float64(i)
+	}
+
+	return terrainToPathXYSpaced(p1, p2, distances, terrain)
+}
+
+// TerrainToPathXYSpaced Converts terrain between two points of set heights into distances from the path between
+// those points, as TerrainToPathXY, for terrain samples at the provided (possibly uneven) distances from the first
+// point. The final distance is the total path distance. An error is returned where the distances and terrain do
+// not match, are empty, or the total path distance is not positive.
+func TerrainToPathXYSpaced(p1, p2 float64, distances []float64, terrain []float64) (x, y []float64, d2 float64, err error) {
+	if len(distances) != len(terrain) {
+		return nil, nil, 0, fmt.Errorf("Terrain profile lengths must match (distances: %d terrain: %d)", len(distances), len(terrain))
+	}
+	if len(terrain) < 2 {
+		return nil, nil, 0, fmt.Errorf("Terrain profile must contain at least two points (points: %d)", len(terrain))
+	}
+	if d := distances[len(distances)-1]; !(d > 0) {
+		return nil, nil, 0, fmt.Errorf("Total path distance must be positive (distance: %.2fm)", d)
+	}
+
+	x, y, d2 = terrainToPathXYSpaced(p1, p2, distances, terrain)
+	return x, y, d2, nil
+}
+
+// terrainToPathXYSpaced converts terrain to path distances as TerrainToPathXYSpaced without validating the inputs
+func terrainToPathXYSpaced(p1, p2 float64, distances []float64, terrain []float64) (x, y []float64, d2 float64) {
+	d := distances[len(distances)-1]
+
+	height := (p2 - p1)
+	θ := math.Atan2(height, d)
+	d2 = math.Sqrt(math.Pow(d, 2) + math.Pow(height, 2))
+
 	x = make([]float64, len(terrain))
 	y = make([]float64, len(terrain))
 
 	for i, terrainHeight := range terrain {
-		offsetHeight := float64(i) * Δh
-		offsetDist := Δd * float64(i)
+		offsetDist := distances[i]
+		offsetHeight := height * offsetDist / d
 
 		verticalClearance := offsetHeight + p1 - terrainHeight
 
@@ -254,3 +395,34 @@ func GraphBullingtonFigure12(filename string, normalised bool, p1, p2 float64, d
 
 	return nil
 }
+
+// ExportPathCSV writes a normalised terrain path (as returned by TerrainToPathXY) with the corresponding
+// fresnel zone radii to CSV, with columns for distance (m), clearance (m) and fresnel radius (m)
+// Clearance is the height of the line of sight above the terrain, the inverse of the normalised y values
+func ExportPathCSV(w io.Writer, x, y []float64, fresnelRadii []float64) error {
+	if len(x) != len(y) || len(x) != len(fresnelRadii) {
+		return fmt.Errorf("Path data lengths must match (x: %d y: %d fresnel radii: %d)", len(x), len(y), len(fresnelRadii))
+	}
+
+	writer := csv.NewWriter(w)
+
+	err := writer.Write([]string{"distance_m", "clearance_m", "fresnel_radius_m"})
+	if err != nil {
+		return err
+	}
+
+	for i := range x {
+		record := []string{
+			strconv.FormatFloat(x[i], 'f', -1, 64),
+			strconv.FormatFloat(-y[i], 'f', -1, 64),
+			strconv.FormatFloat(fresnelRadii[i], 'f', -1, 64),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
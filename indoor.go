@@ -0,0 +1,157 @@
+package rf
+
+import (
+	"fmt"
+	"math"
+)
+
+// Indoor propagation models
+
+// WallType is a type of interior or exterior wall
+type WallType string
+
+// Supported wall types
+const (
+	WallDrywall  WallType = "drywall"
+	WallConcrete WallType = "concrete"
+	WallGlass    WallType = "glass"
+)
+
+// wallLosses are typical penetration losses in dB for each wall type (at ~1-5GHz)
+var wallLosses = map[WallType]Attenuation{
+	WallDrywall:  3,
+	WallConcrete: 12,
+	WallGlass:    2,
+}
+
+const (
+	// IndoorPathLossExponent is the log-distance path loss exponent used for indoor partition loss
+	IndoorPathLossExponent = 3.0
+	// IndoorFloorLoss is the penetration loss in dB per floor used for indoor partition loss
+	IndoorFloorLoss = 15.0
+)
+
+// IndoorPartitionLoss calculates indoor path loss by counting the walls and floors penetrated by a path, adding
+// typical penetration losses for each to a log-distance (IndoorPathLossExponent, 1m reference) base loss.
+// Unknown wall types are ignored.
+func IndoorPartitionLoss(freq Frequency, distance Distance, walls []WallType, floors int) Attenuation {
+	loss := CalculateLogDistancePathLoss(freq, distance, 1*M, IndoorPathLossExponent)
+
+	for _, w := range walls {
+		loss += wallLosses[w]
+	}
+	loss += Attenuation(floors) * IndoorFloorLoss
+
+	return loss
+}
+
+// IndoorEnv is an indoor environment type for the ITU-R P.1238 model
+type IndoorEnv string
+
+// Supported indoor environments
+const (
+	IndoorResidential IndoorEnv = "residential"
+	IndoorOffice      IndoorEnv = "office"
+	IndoorCommercial  IndoorEnv = "commercial"
+)
+
+// p1238Coefficients are the ITU-R P.1238 distance power loss coefficient and floor penetration loss factor for an
+// environment, with floor loss either tabulated by number of floors or linear (base and per additional floor)
+type p1238Coefficients struct {
+	n                 float64
+	floorBase, floorN float64
+	floorTable        []float64
+}
+
+// floorLoss calculates the floor penetration loss factor for the provided number of floors, returning false where
+// the recommendation does not provide a factor
+func (c p1238Coefficients) floorLoss(floors int) (float64, bool) {
+	switch {
+	case floors == 0:
+		return 0, true
+	case c.floorTable != nil:
+		if floors > len(c.floorTable) {
+			return 0, false
+		}
+		return c.floorTable[floors-1], true
+	case c.floorBase == 0 && c.floorN == 0:
+		return 0, false
+	}
+	return c.floorBase + c.floorN*float64(floors-1), true
+}
+
+// p1238Band holds the ITU-R P.1238 coefficients for each environment tabulated at a frequency, applied to
+// frequencies between min and max
+type p1238Band struct {
+	min, max     Frequency
+	environments map[IndoorEnv]p1238Coefficients
+}
+
+// p1238Bands are the ITU-R P.1238 coefficients for each tabulated frequency (900MHz, 1.2-1.3GHz, 1.8-2GHz, 4GHz and
+// 5.2GHz), with band edges between the tabulated frequencies so that the nearest coefficients are used
+var p1238Bands = []p1238Band{
+	{P1238MinFrequency, 1.05 * GHz, map[IndoorEnv]p1238Coefficients{
+		IndoorOffice:     {n: 33, floorTable: []float64{9, 19, 24}},
+		IndoorCommercial: {n: 20},
+	}},
+	{1.05 * GHz, 1.5 * GHz, map[IndoorEnv]p1238Coefficients{
+		IndoorOffice:     {n: 32},
+		IndoorCommercial: {n: 22},
+	}},
+	{1.5 * GHz, 2.8 * GHz, map[IndoorEnv]p1238Coefficients{
+		IndoorResidential: {n: 28, floorBase: 4, floorN: 4},
+		IndoorOffice:      {n: 30, floorBase: 15, floorN: 4},
+		IndoorCommercial:  {n: 22, floorBase: 6, floorN: 3},
+	}},
+	{2.8 * GHz, 4.6 * GHz, map[IndoorEnv]p1238Coefficients{
+		IndoorOffice:     {n: 28},
+		IndoorCommercial: {n: 22},
+	}},
+	{4.6 * GHz, P1238MaxFrequency, map[IndoorEnv]p1238Coefficients{
+		IndoorOffice: {n: 31, floorTable: []float64{16}},
+	}},
+}
+
+// ITU-R P.1238 frequency limits
+const (
+	P1238MinFrequency = 900 * MHz
+	P1238MaxFrequency = 5.2 * GHz
+)
+
+// IndoorLossP1238 calculates the indoor path loss between terminals separated by distance (greater than 1m) and
+// the provided number of floors using the ITU-R P.1238 site-general model, L = 20log(f) + N·log(d) + Lf(n) - 28
+// with f in MHz. Coefficients are taken from the nearest tabulated frequency between P1238MinFrequency and
+// P1238MaxFrequency, returning an error where the recommendation does not provide coefficients for the
+// environment or number of floors at that frequency.
+func IndoorLossP1238(freq Frequency, distance Distance, floors int, env IndoorEnv) (Attenuation, error) {
+	if freq < P1238MinFrequency || freq > P1238MaxFrequency {
+		return 0, fmt.Errorf("ITU-R P.1238 requires a frequency between %.0fMHz and %.0fMHz (frequency: %.2fMHz)",
+			P1238MinFrequency/MHz, P1238MaxFrequency/MHz, freq/MHz)
+	}
+	if distance <= 1 {
+		return 0, fmt.Errorf("ITU-R P.1238 requires a distance greater than 1m (distance: %.2fm)", distance)
+	}
+	if floors < 0 {
+		return 0, fmt.Errorf("Number of floors must not be negative (floors: %d)", floors)
+	}
+
+	var band p1238Band
+	for _, band = range p1238Bands {
+		if freq <= band.max {
+			break
+		}
+	}
+
+	coefficients, ok := band.environments[env]
+	if !ok {
+		return 0, fmt.Errorf("Unsupported indoor environment at %.0fMHz: %s", freq/MHz, env)
+	}
+	floorLoss, ok := coefficients.floorLoss(floors)
+	if !ok {
+		return 0, fmt.Errorf("ITU-R P.1238 provides no %s floor penetration factor for %d floors at %.0fMHz", env, floors, freq/MHz)
+	}
+
+	loss := 20*math.Log10(float64(freq/MHz)) + coefficients.n*math.Log10(float64(distance)) + floorLoss - 28
+
+	return Attenuation(loss), nil
+}
@@ -0,0 +1,99 @@
+package rf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndoor(t *testing.T) {
+
+	t.Run("Can calculate indoor partition loss", func(t *testing.T) {
+		base := CalculateLogDistancePathLoss(2.4*GHz, 20, 1, IndoorPathLossExponent)
+		assert.InDelta(t, 40.05+39.03, float64(base), 0.01)
+
+		// Two concrete walls and one floor
+		loss := IndoorPartitionLoss(2.4*GHz, 20, []WallType{WallConcrete, WallConcrete}, 1)
+		assert.InDelta(t, float64(base)+12+12+15, float64(loss), allowedError)
+	})
+
+	t.Run("Partition loss without obstructions is the base loss", func(t *testing.T) {
+		base := CalculateLogDistancePathLoss(5*GHz, 10, 1, IndoorPathLossExponent)
+		assert.InDelta(t, float64(base), float64(IndoorPartitionLoss(5*GHz, 10, nil, 0)), allowedError)
+	})
+
+	t.Run("Concrete walls attenuate more than drywall and glass", func(t *testing.T) {
+		concrete := IndoorPartitionLoss(2.4*GHz, 20, []WallType{WallConcrete}, 0)
+		drywall := IndoorPartitionLoss(2.4*GHz, 20, []WallType{WallDrywall}, 0)
+		glass := IndoorPartitionLoss(2.4*GHz, 20, []WallType{WallGlass}, 0)
+
+		assert.True(t, concrete > drywall)
+		assert.True(t, drywall > glass)
+	})
+
+	t.Run("Can calculate ITU-R P.1238 loss for office and residential environments", func(t *testing.T) {
+		// 20log(2000) + 30log(20) - 28
+		office, err := IndoorLossP1238(2*GHz, 20, 0, IndoorOffice)
+		assert.Nil(t, err)
+		assert.InDelta(t, 66.02+39.03-28, float64(office), 0.01)
+
+		// 20log(2000) + 28log(20) - 28
+		residential, err := IndoorLossP1238(2*GHz, 20, 0, IndoorResidential)
+		assert.Nil(t, err)
+		assert.InDelta(t, 66.02+36.43-28, float64(residential), 0.01)
+
+		assert.True(t, office > residential)
+	})
+
+	t.Run("ITU-R P.1238 applies floor penetration factors", func(t *testing.T) {
+		base, _ := IndoorLossP1238(2*GHz, 20, 0, IndoorOffice)
+
+		oneFloor, err := IndoorLossP1238(2*GHz, 20, 1, IndoorOffice)
+		assert.Nil(t, err)
+		assert.InDelta(t, 15.0, float64(oneFloor-base), allowedError)
+
+		threeFloors, _ := IndoorLossP1238(2*GHz, 20, 3, IndoorOffice)
+		assert.InDelta(t, 15.0+4+4, float64(threeFloors-base), allowedError)
+
+		residentialBase, _ := IndoorLossP1238(2*GHz, 20, 0, IndoorResidential)
+		residential, _ := IndoorLossP1238(2*GHz, 20, 2, IndoorResidential)
+		assert.InDelta(t, 8.0, float64(residential-residentialBase), allowedError)
+	})
+
+	t.Run("ITU-R P.1238 uses coefficients for the frequency band", func(t *testing.T) {
+		// 20log(900) + 33log(20) + 9 - 28
+		office, err := IndoorLossP1238(900*MHz, 20, 1, IndoorOffice)
+		assert.Nil(t, err)
+		assert.InDelta(t, 59.08+42.93+9-28, float64(office), 0.01)
+
+		threeFloors, _ := IndoorLossP1238(900*MHz, 20, 3, IndoorOffice)
+		assert.InDelta(t, 24.0-9, float64(threeFloors-office), allowedError)
+
+		// 20log(5200) + 31log(20) + 16 - 28
+		office, err = IndoorLossP1238(5.2*GHz, 20, 1, IndoorOffice)
+		assert.Nil(t, err)
+		assert.InDelta(t, 74.32+40.33+16-28, float64(office), 0.01)
+
+		// No residential coefficients or factors beyond tabulated floors
+		_, err = IndoorLossP1238(900*MHz, 20, 0, IndoorResidential)
+		assert.NotNil(t, err)
+		_, err = IndoorLossP1238(900*MHz, 20, 4, IndoorOffice)
+		assert.NotNil(t, err)
+		_, err = IndoorLossP1238(5.2*GHz, 20, 2, IndoorOffice)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ITU-R P.1238 rejects invalid parameters", func(t *testing.T) {
+		_, err := IndoorLossP1238(433*MHz, 20, 0, IndoorOffice)
+		assert.NotNil(t, err)
+
+		_, err = IndoorLossP1238(28*GHz, 20, 0, IndoorOffice)
+		assert.NotNil(t, err)
+
+		_, err = IndoorLossP1238(2*GHz, 0.5, 0, IndoorOffice)
+		assert.NotNil(t, err)
+
+		_, err = IndoorLossP1238(2*GHz, 20, 0, "warehouse")
+		assert.NotNil(t, err)
+	})
+}
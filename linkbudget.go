@@ -0,0 +1,267 @@
+package rf
+
+import (
+	"fmt"
+)
+
+// Link budget calculations
+// https://en.wikipedia.org/wiki/Link_budget
+
+// LinkBudget describes a point to point radio link
+type LinkBudget struct {
+	// Frequency is the link frequency
+	Frequency Frequency
+	// Distance is the distance between the transmitter and receiver
+	Distance Distance
+	// TxHeight and RxHeight are the antenna heights above ground in meters
+	TxHeight, RxHeight float64
+	// TxPowerDBm is the transmit power in dBm
+	TxPowerDBm float64
+	// TxGainDBi and RxGainDBi are the transmit and receive antenna gains in dBi
+	TxGainDBi, RxGainDBi float64
+	// RxSensitivityDBm is the minimum received power in dBm for the link to operate
+	RxSensitivityDBm float64
+	// LossesDB is the sum of other (cable, connector, etc.) losses in dB
+	LossesDB float64
+	// Model is the propagation model used to calculate path loss, FreeSpaceModel if nil
+	Model PropagationModel
+}
+
+// LinkBudgetResult is the outcome of solving a link budget
+type LinkBudgetResult struct {
+	// PathLoss is the propagation loss between the transmitter and receiver
+	PathLoss Attenuation
+	// EIRPDBm is the effective isotropic radiated power in dBm
+	EIRPDBm float64
+	// RxPowerDBm is the received power in dBm
+	RxPowerDBm float64
+	// FadeMarginDB is the received power in excess of the receiver sensitivity in dB
+	FadeMarginDB float64
+}
+
+// Solve calculates the received power and fade margin for a link budget
+func (lb LinkBudget) Solve() LinkBudgetResult {
+	model := lb.Model
+	if model == nil {
+		model = FreeSpaceModel{}
+	}
+
+	pathLoss := model.PathLoss(lb.Frequency, Geometry{Distance: lb.Distance, TxHeight: lb.TxHeight, RxHeight: lb.RxHeight})
+
+	eirp := lb.TxPowerDBm + lb.TxGainDBi
+	rxPower := eirp - float64(pathLoss) - lb.LossesDB + lb.RxGainDBi
+
+	return LinkBudgetResult{
+		PathLoss:     pathLoss,
+		EIRPDBm:      eirp,
+		RxPowerDBm:   rxPower,
+		FadeMarginDB: rxPower - lb.RxSensitivityDBm,
+	}
+}
+
+// MaxRange calculates the distance at which the received power falls to the provided receiver sensitivity in dBm
+// (ie. zero fade margin), by numerically inverting the received power over distance for the link propagation model
+func (lb LinkBudget) MaxRange(rxSensitivityDBm float64) (Distance, error) {
+	return lb.MaxRangeWithOptions(rxSensitivityDBm, DefaultSolverOptions)
+}
+
+// MaxRangeWithOptions calculates the maximum range as MaxRange, using the provided solver options with the tolerance
+// in meters
+func (lb LinkBudget) MaxRangeWithOptions(rxSensitivityDBm float64, opts SolverOptions) (Distance, error) {
+	lb.RxSensitivityDBm = rxSensitivityDBm
+	margin := func(d Distance) float64 {
+		lb.Distance = d
+		return lb.Solve().FadeMarginDB
+	}
+
+	// Bracket the range between a short distance and the first distance at which the link fails to close
+	lo, hi := 1*M, 2*M
+	if margin(lo) < 0 {
+		return 0, fmt.Errorf("Link does not close at %.2fm (sensitivity: %.2fdBm)", lo, rxSensitivityDBm)
+	}
+	for margin(hi) >= 0 {
+		lo, hi = hi, hi*2
+		if hi > maxRangeSearchLimit {
+			return 0, fmt.Errorf("Link closes beyond %.2fm (sensitivity: %.2fdBm)", maxRangeSearchLimit, rxSensitivityDBm)
+		}
+	}
+
+	// Find the distance at which the fade margin reaches zero
+	d, err := Brent(func(d float64) float64 {
+		return margin(Distance(d))
+	}, float64(lo), float64(hi), opts)
+	if err != nil {
+		return 0, fmt.Errorf("Range search failed: %w", err)
+	}
+
+	return Distance(d), nil
+}
+
+// RequiredGain calculates the antenna gain in dBi required to close a free space link (ie. zero fade margin) between
+// a transmitter and a receiver with the provided sensitivity. Where splitEvenly is set the total gain is shared
+// equally between the two ends, otherwise it is allocated to the receiver so as not to increase the EIRP.
+// Negative gains indicate the link closes with margin using isotropic antennas.
+func RequiredGain(txPowerDBm, rxSensitivityDBm float64, freq Frequency, distance Distance, otherLossesDB float64, splitEvenly bool) (txGain, rxGain float64) {
+	total := rxSensitivityDBm - txPowerDBm + float64(CalculateFreeSpacePathLoss(freq, distance)) + otherLossesDB
+
+	if splitEvenly {
+		return total / 2, total / 2
+	}
+	return 0, total
+}
+
+// Link budget parameters for SensitivityAnalysis
+const (
+	LinkParamTxPower  = "tx_power"
+	LinkParamDistance = "distance"
+	LinkParamTxGain   = "tx_gain"
+	LinkParamRxGain   = "rx_gain"
+	LinkParamLosses   = "losses"
+)
+
+// SensitivityAnalysis sweeps a single link budget parameter (one of the LinkParam constants) by delta for
+// the provided number of steps, returning the solved link budget at each step with the first result being
+// the unmodified link budget. Unknown parameters or non-positive step counts return nil.
+func SensitivityAnalysis(lb LinkBudget, param string, delta float64, steps int) []LinkBudgetResult {
+	if steps <= 0 {
+		return nil
+	}
+
+	results := make([]LinkBudgetResult, steps)
+
+	for i := range results {
+		offset := float64(i) * delta
+		swept := lb
+
+		switch param {
+		case LinkParamTxPower:
+			swept.TxPowerDBm += offset
+		case LinkParamDistance:
+			swept.Distance += Distance(offset)
+		case LinkParamTxGain:
+			swept.TxGainDBi += offset
+		case LinkParamRxGain:
+			swept.RxGainDBi += offset
+		case LinkParamLosses:
+			swept.LossesDB += offset
+		default:
+			return nil
+		}
+
+		results[i] = swept.Solve()
+	}
+
+	return results
+}
+
+// MultiHopResult is the outcome of evaluating a multi-hop relay link
+type MultiHopResult struct {
+	// Hops contains the solved link budget for each hop
+	Hops []LinkBudgetResult
+	// WeakestHop is the index of the hop with the lowest fade margin
+	WeakestHop int
+	// FadeMarginDB is the fade margin of the weakest hop
+	FadeMarginDB float64
+	// Feasible is true where every hop has a non-negative fade margin
+	Feasible bool
+}
+
+// MultiHopLinkBudget evaluates a chain of relayed links, reporting the weakest hop and whether the
+// end to end link is feasible (ie. every hop closes)
+func MultiHopLinkBudget(hops []LinkBudget) (MultiHopResult, error) {
+	if len(hops) == 0 {
+		return MultiHopResult{}, fmt.Errorf("Multi-hop link budget requires at least one hop")
+	}
+
+	result := MultiHopResult{Hops: make([]LinkBudgetResult, len(hops))}
+
+	for i, hop := range hops {
+		result.Hops[i] = hop.Solve()
+
+		if i == 0 || result.Hops[i].FadeMarginDB < result.FadeMarginDB {
+			result.WeakestHop = i
+			result.FadeMarginDB = result.Hops[i].FadeMarginDB
+		}
+	}
+
+	result.Feasible = result.FadeMarginDB >= 0
+
+	return result, nil
+}
+
+// maxRangeSearchLimit is the largest distance considered when solving for link range
+const maxRangeSearchLimit = 1e+9 * M
+
+// MaxRangeForDataRate calculates the distance at which the Shannon capacity of a link falls to the target data rate
+// (bits per second), given the receiver bandwidth and noise figure. The link budget distance is ignored and the
+// range is found by numerically inverting the signal to noise ratio over distance for the link propagation model.
+func MaxRangeForDataRate(targetBps float64, lb LinkBudget, bandwidthHz, noiseFigureDB float64) (Distance, error) {
+	return MaxRangeForDataRateWithOptions(targetBps, lb, bandwidthHz, noiseFigureDB, DefaultSolverOptions)
+}
+
+// MaxRangeForDataRateWithOptions calculates the maximum range as MaxRangeForDataRate, using the provided solver options
+// with the tolerance in meters
+func MaxRangeForDataRateWithOptions(targetBps float64, lb LinkBudget, bandwidthHz, noiseFigureDB float64, opts SolverOptions) (Distance, error) {
+	if targetBps <= 0 || bandwidthHz <= 0 {
+		return 0, fmt.Errorf("Target data rate and bandwidth must be positive (rate: %.2fbps bandwidth: %.2fHz)", targetBps, bandwidthHz)
+	}
+
+	noise := ThermalNoiseDBm(bandwidthHz, noiseFigureDB)
+	capacity := func(d Distance) float64 {
+		lb.Distance = d
+		return ShannonCapacity(bandwidthHz, lb.Solve().RxPowerDBm-noise)
+	}
+
+	// Bracket the range between a short distance and the first distance that fails to meet the target
+	lo, hi := 1*M, 2*M
+	if capacity(lo) < targetBps {
+		return 0, fmt.Errorf("Target data rate of %.2fbps is not achievable at %.2fm", targetBps, lo)
+	}
+	for capacity(hi) >= targetBps {
+		lo, hi = hi, hi*2
+		if hi > maxRangeSearchLimit {
+			return 0, fmt.Errorf("Target data rate of %.2fbps is achievable beyond %.2fm", targetBps, maxRangeSearchLimit)
+		}
+	}
+
+	// Find the distance at which the capacity meets the target
+	d, err := Brent(func(d float64) float64 {
+		return capacity(Distance(d)) - targetBps
+	}, float64(lo), float64(hi), opts)
+	if err != nil {
+		return 0, fmt.Errorf("Range search failed: %w", err)
+	}
+
+	return Distance(d), nil
+}
+
+// LoRaNoiseFigure is the typical LoRa receiver noise figure in dB
+const LoRaNoiseFigure = 6.0
+
+// LoRaDespreadSNR is the SNR in dB required by the LoRa demodulator after despreading, such that the demodulator SNR
+// limit is LoRaDespreadSNR less LoRaProcessingGain (matching the -7.5dB SF7 limit of the Semtech SX1276 datasheet)
+const LoRaDespreadSNR = 5.12
+
+// LoRaLinkBudget evaluates a free space LoRa link with isotropic antennas, calculating the receiver sensitivity
+// from the bandwidth and spreading factor (SF6 to SF12) using the demodulator SNR limit from LoRaProcessingGain
+// and LoRaDespreadSNR, and LoRaNoiseFigure
+// https://en.wikipedia.org/wiki/LoRa
+func LoRaLinkBudget(txPowerDBm float64, freq Frequency, bandwidthHz float64, spreadingFactor int, distance Distance) (LinkBudgetResult, error) {
+	if spreadingFactor < 6 || spreadingFactor > 12 {
+		return LinkBudgetResult{}, fmt.Errorf("LoRa spreading factor must be between 6 and 12 (spreading factor: %d)", spreadingFactor)
+	}
+	if bandwidthHz <= 0 {
+		return LinkBudgetResult{}, fmt.Errorf("LoRa bandwidth must be positive (bandwidth: %.2fHz)", bandwidthHz)
+	}
+
+	snrLimit := LoRaDespreadSNR - float64(LoRaProcessingGain(spreadingFactor))
+
+	lb := LinkBudget{
+		Frequency:        freq,
+		Distance:         distance,
+		TxPowerDBm:       txPowerDBm,
+		RxSensitivityDBm: ReceiverSensitivity(bandwidthHz, LoRaNoiseFigure, snrLimit),
+	}
+
+	return lb.Solve(), nil
+}
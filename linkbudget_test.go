@@ -0,0 +1,223 @@
+package rf
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Example 433MHz link over 1km
+var exampleLink = LinkBudget{
+	Frequency:        433 * MHz,
+	Distance:         1 * Km,
+	TxPowerDBm:       14,
+	TxGainDBi:        2,
+	RxGainDBi:        2,
+	RxSensitivityDBm: -110,
+	LossesDB:         1,
+}
+
+func TestLinkBudget(t *testing.T) {
+
+	t.Run("Can solve a free space link budget", func(t *testing.T) {
+		result := exampleLink.Solve()
+
+		assert.InDelta(t, 85.178, float64(result.PathLoss), allowedError)
+		assert.InDelta(t, 16.0, result.EIRPDBm, allowedError)
+		assert.InDelta(t, 14+2+2-1-85.178, result.RxPowerDBm, allowedError)
+		assert.InDelta(t, 14+2+2-1-85.178+110, result.FadeMarginDB, allowedError)
+	})
+
+	t.Run("Maximum range inverts free space path loss", func(t *testing.T) {
+		d, err := exampleLink.MaxRange(-110)
+		assert.Nil(t, err)
+
+		// Path loss at the maximum range consumes the full budget
+		budget := 14 + 2 + 2 - 1 + 110.0
+		assert.InDelta(t, budget, float64(CalculateFreeSpacePathLoss(433*MHz, d)), 1e-6)
+
+		// The link closes with zero margin at the maximum range
+		lb := exampleLink
+		lb.Distance = d
+		assert.InDelta(t, 0.0, lb.Solve().FadeMarginDB, 1e-6)
+
+		// The range is independent of the link distance
+		lb.Distance = 50 * Km
+		other, err := lb.MaxRange(-110)
+		assert.Nil(t, err)
+		assert.InDelta(t, float64(d), float64(other), 1e-3)
+	})
+
+	t.Run("Maximum range uses the link propagation model", func(t *testing.T) {
+		lb := exampleLink
+		lb.Model = LogDistanceModel{Reference: 100 * M, Exponent: 3.5}
+
+		d, err := lb.MaxRange(-110)
+		assert.Nil(t, err)
+
+		freeSpace, _ := exampleLink.MaxRange(-110)
+		assert.True(t, d < freeSpace)
+
+		lb.Distance = d
+		assert.InDelta(t, 0.0, lb.Solve().FadeMarginDB, 1e-6)
+	})
+
+	t.Run("Maximum range uses the provided solver options", func(t *testing.T) {
+		fine, _ := exampleLink.MaxRange(-110)
+
+		coarse, err := exampleLink.MaxRangeWithOptions(-110, SolverOptions{MaxIterations: 100, Tolerance: 10})
+		assert.Nil(t, err)
+		assert.InDelta(t, float64(fine), float64(coarse), 10)
+
+		_, err = exampleLink.MaxRangeWithOptions(-110, SolverOptions{MaxIterations: 1, Tolerance: 1e-12})
+		assert.NotNil(t, err)
+
+		_, err = MaxRangeForDataRateWithOptions(54e+6, LinkBudget{Frequency: 2.4 * GHz, TxPowerDBm: 20}, 20e+6, 6, SolverOptions{})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Maximum range fails where the link never closes", func(t *testing.T) {
+		_, err := exampleLink.MaxRange(20)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Can calculate the antenna gain required to close a link", func(t *testing.T) {
+		marginal := LinkBudget{Frequency: 2.4 * GHz, Distance: 10 * Km, TxPowerDBm: 20, RxSensitivityDBm: -95, LossesDB: 3}
+
+		txGain, rxGain := RequiredGain(20, -95, 2.4*GHz, 10*Km, 3, true)
+		assert.InDelta(t, txGain, rxGain, allowedError)
+		assert.InDelta(t, 8.05, txGain+rxGain, 0.01)
+
+		// The required gains exactly close the link
+		marginal.TxGainDBi, marginal.RxGainDBi = txGain, rxGain
+		assert.InDelta(t, 0.0, marginal.Solve().FadeMarginDB, allowedError)
+
+		// Unsplit gain is allocated to the receiver
+		txGain, rxGain = RequiredGain(20, -95, 2.4*GHz, 10*Km, 3, false)
+		assert.Equal(t, 0.0, txGain)
+		marginal.TxGainDBi, marginal.RxGainDBi = txGain, rxGain
+		assert.InDelta(t, 0.0, marginal.Solve().FadeMarginDB, allowedError)
+	})
+
+	t.Run("Required gain is negative for links with margin", func(t *testing.T) {
+		txGain, rxGain := RequiredGain(14, -110, 433*MHz, 1*Km, 1, true)
+		assert.InDelta(t, -110-14+85.178+1, txGain+rxGain, allowedError)
+		assert.True(t, txGain < 0)
+	})
+
+	t.Run("Sensitivity analysis margin decreases with distance", func(t *testing.T) {
+		results := SensitivityAnalysis(exampleLink, LinkParamDistance, 500, 10)
+		assert.Len(t, results, 10)
+
+		assert.Equal(t, exampleLink.Solve(), results[0])
+		for i := 1; i < len(results); i++ {
+			assert.True(t, results[i].FadeMarginDB < results[i-1].FadeMarginDB, "index %d", i)
+		}
+	})
+
+	t.Run("Sensitivity analysis margin tracks tx power", func(t *testing.T) {
+		results := SensitivityAnalysis(exampleLink, LinkParamTxPower, 1, 5)
+		assert.Len(t, results, 5)
+
+		for i := range results {
+			assert.InDelta(t, results[0].FadeMarginDB+float64(i), results[i].FadeMarginDB, allowedError, "index %d", i)
+		}
+	})
+
+	t.Run("Sensitivity analysis rejects unknown parameters", func(t *testing.T) {
+		assert.Nil(t, SensitivityAnalysis(exampleLink, "bandwidth", 1, 5))
+		assert.Nil(t, SensitivityAnalysis(exampleLink, LinkParamDistance, 500, 0))
+		assert.Nil(t, SensitivityAnalysis(exampleLink, LinkParamDistance, 500, -1))
+	})
+
+	t.Run("Multi-hop link budget reports the limiting hop", func(t *testing.T) {
+		second := exampleLink
+		second.Distance = 5 * Km
+
+		result, err := MultiHopLinkBudget([]LinkBudget{exampleLink, second})
+		assert.Nil(t, err)
+
+		assert.Len(t, result.Hops, 2)
+		assert.Equal(t, 1, result.WeakestHop)
+		assert.InDelta(t, second.Solve().FadeMarginDB, result.FadeMarginDB, allowedError)
+		assert.True(t, result.Feasible)
+	})
+
+	t.Run("Multi-hop link budget is infeasible if any hop fails", func(t *testing.T) {
+		second := exampleLink
+		second.Distance = 500 * Km
+
+		result, err := MultiHopLinkBudget([]LinkBudget{exampleLink, second, exampleLink})
+		assert.Nil(t, err)
+
+		assert.Equal(t, 1, result.WeakestHop)
+		assert.True(t, result.FadeMarginDB < 0)
+		assert.False(t, result.Feasible)
+	})
+
+	t.Run("Multi-hop link budget requires hops", func(t *testing.T) {
+		_, err := MultiHopLinkBudget([]LinkBudget{})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Can calculate maximum range for a data rate", func(t *testing.T) {
+		wifi := LinkBudget{Frequency: 2.4 * GHz, TxPowerDBm: 20, TxGainDBi: 2, RxGainDBi: 2}
+
+		last := Distance(math.Inf(1))
+		for _, rate := range []float64{1e+6, 10e+6, 50e+6, 100e+6, 200e+6} {
+			d, err := MaxRangeForDataRate(rate, wifi, 20e+6, 6)
+			assert.Nil(t, err)
+
+			// Range decreases as the target rate increases
+			assert.True(t, d < last, "rate %.0f", rate)
+			last = d
+
+			// Capacity at the returned range meets the target
+			wifi.Distance = d
+			snr := wifi.Solve().RxPowerDBm - ThermalNoiseDBm(20e+6, 6)
+			assert.InDelta(t, rate, ShannonCapacity(20e+6, snr), rate*1e-6)
+		}
+	})
+
+	t.Run("Maximum range fails for unachievable rates", func(t *testing.T) {
+		wifi := LinkBudget{Frequency: 2.4 * GHz, TxPowerDBm: 20, TxGainDBi: 2, RxGainDBi: 2}
+
+		_, err := MaxRangeForDataRate(10e+9, wifi, 20e+6, 6)
+		assert.NotNil(t, err)
+
+		_, err = MaxRangeForDataRate(0, wifi, 20e+6, 6)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestLoRaLinkBudget(t *testing.T) {
+
+	t.Run("SF12 extends range over SF7 at 868MHz", func(t *testing.T) {
+		sf7, err := LoRaLinkBudget(14, 868*MHz, 125e+3, 7, 100*Km)
+		assert.Nil(t, err)
+		sf12, err := LoRaLinkBudget(14, 868*MHz, 125e+3, 12, 100*Km)
+		assert.Nil(t, err)
+
+		// Path loss is independent of the spreading factor, the SNR limit improves by the processing gain
+		assert.InDelta(t, float64(sf7.PathLoss), float64(sf12.PathLoss), allowedError)
+		assert.InDelta(t, float64(LoRaProcessingGain(12)-LoRaProcessingGain(7)), sf12.FadeMarginDB-sf7.FadeMarginDB, allowedError)
+		assert.InDelta(t, -124.51, sf7.RxPowerDBm-sf7.FadeMarginDB, 0.01)
+
+		// Within 0.25dB of the 12.5dB published improvement (Semtech SX1276 datasheet)
+		assert.InDelta(t, 12.5, sf12.FadeMarginDB-sf7.FadeMarginDB, 0.25)
+
+		// 12.5dB corresponds to a free space range increase of 10^(12.5/20) ~= 4.2x
+		sf7, _ = LoRaLinkBudget(14, 868*MHz, 125e+3, 7, 400*Km)
+		sf12, _ = LoRaLinkBudget(14, 868*MHz, 125e+3, 12, 400*Km)
+		assert.True(t, sf7.FadeMarginDB < 0)
+		assert.True(t, sf12.FadeMarginDB > 0)
+	})
+
+	t.Run("Rejects invalid LoRa parameters", func(t *testing.T) {
+		_, err := LoRaLinkBudget(14, 868*MHz, 125e+3, 13, 1*Km)
+		assert.NotNil(t, err)
+		_, err = LoRaLinkBudget(14, 868*MHz, 0, 7, 1*Km)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,126 @@
+package rf
+
+import (
+	"math"
+	"strings"
+)
+
+// Modulation helpers
+
+// OccupiedBandwidth calculates the occupied bandwidth ((1+α)·Rs) of a raised cosine filtered signal
+// with the provided symbol rate (symbols per second) and roll-off factor
+// https://en.wikipedia.org/wiki/Raised-cosine_filter
+func OccupiedBandwidth(symbolRate float64, rolloff float64) Frequency {
+	return Frequency((1 + rolloff) * symbolRate)
+}
+
+// SymbolRateFromBandwidth calculates the symbol rate (symbols per second) of a raised cosine filtered signal
+// occupying the provided bandwidth with the provided roll-off factor
+func SymbolRateFromBandwidth(bandwidth Frequency, rolloff float64) float64 {
+	return float64(bandwidth) / (1 + rolloff)
+}
+
+// ProcessingGain calculates the processing gain of a spread spectrum signal from the chip rate and data rate
+// (in chips and bits per second), reducing the SNR required at the receiver by the returned value
+// https://en.wikipedia.org/wiki/Processing_gain
+func ProcessingGain(chipRate, dataRate float64) Attenuation {
+	return Attenuation(10 * math.Log10(chipRate/dataRate))
+}
+
+// LoRaProcessingGain calculates the processing gain of a LoRa chirp spread spectrum signal for the provided
+// spreading factor, where each symbol of 2^SF chips carries SF bits (prior to forward error correction)
+// https://en.wikipedia.org/wiki/LoRa
+func LoRaProcessingGain(spreadingFactor int) Attenuation {
+	chips := math.Pow(2, float64(spreadingFactor))
+	return ProcessingGain(chips, float64(spreadingFactor))
+}
+
+// Modulation is a digital modulation scheme
+type Modulation string
+
+// Supported modulation schemes
+const (
+	ModulationBPSK   Modulation = "BPSK"
+	ModulationQPSK   Modulation = "QPSK"
+	Modulation8PSK   Modulation = "8PSK"
+	Modulation16PSK  Modulation = "16PSK"
+	Modulation16QAM  Modulation = "16QAM"
+	Modulation64QAM  Modulation = "64QAM"
+	Modulation256QAM Modulation = "256QAM"
+)
+
+// modulationOrders is the constellation size of each supported modulation scheme
+var modulationOrders = map[Modulation]int{
+	ModulationBPSK:   2,
+	ModulationQPSK:   4,
+	Modulation8PSK:   8,
+	Modulation16PSK:  16,
+	Modulation16QAM:  16,
+	Modulation64QAM:  64,
+	Modulation256QAM: 256,
+}
+
+// isQAM returns whether a modulation scheme is square quadrature amplitude modulation
+func (m Modulation) isQAM() bool {
+	return strings.HasSuffix(string(m), "QAM")
+}
+
+// QFunction calculates the tail probability of the standard normal distribution
+// https://en.wikipedia.org/wiki/Q-function
+func QFunction(x float64) float64 {
+	return 0.5 * math.Erfc(x/math.Sqrt2)
+}
+
+// berCoefficients returns the coefficients of the bit error rate approximation a·Q(√(c·Eb/N0)) for a
+// modulation scheme, using the standard nearest neighbour approximations with Gray coding for M-PSK (M > 4) and M-QAM
+func berCoefficients(scheme Modulation) (a, c float64, ok bool) {
+	order, ok := modulationOrders[scheme]
+	if !ok {
+		return 0, 0, false
+	}
+
+	m := float64(order)
+	k := math.Log2(m)
+
+	switch {
+	case order <= 4:
+		// BPSK and Gray coded QPSK have the same bit error rate
+		return 1, 2, true
+	case scheme.isQAM():
+		return 4 / k * (1 - 1/math.Sqrt(m)), 3 * k / (m - 1), true
+	default:
+		return 2 / k, 2 * k * math.Pow(math.Sin(π/m), 2), true
+	}
+}
+
+// BERAWGN calculates the theoretical bit error rate of a modulation scheme over an additive white gaussian noise
+// channel with the provided Eb/N0 in dB. M-PSK (M > 4) and M-QAM use the standard nearest neighbour approximations
+// with Gray coding, and unknown schemes return NaN.
+// https://en.wikipedia.org/wiki/Bit_error_rate
+// https://en.wikipedia.org/wiki/Phase-shift_keying#Bit_error_rate
+func BERAWGN(scheme Modulation, ebN0dB float64) float64 {
+	a, c, ok := berCoefficients(scheme)
+	if !ok {
+		return math.NaN()
+	}
+
+	ebN0 := math.Pow(10, ebN0dB/10)
+
+	return a * QFunction(math.Sqrt(c*ebN0))
+}
+
+// BERRayleigh calculates the average bit error rate of a modulation scheme over a flat Rayleigh fading channel
+// (without diversity) with the provided average Eb/N0 in dB, by averaging the AWGN error rate over the exponentially
+// distributed instantaneous SNR. At high SNR this falls only as 1/SNR, in contrast to the exponential fall over AWGN.
+// Unknown schemes return NaN.
+// https://en.wikipedia.org/wiki/Rayleigh_fading
+func BERRayleigh(scheme Modulation, avgEbN0dB float64) float64 {
+	a, c, ok := berCoefficients(scheme)
+	if !ok {
+		return math.NaN()
+	}
+
+	γ := c * math.Pow(10, avgEbN0dB/10) / 2
+
+	return a / 2 * (1 - math.Sqrt(γ/(1+γ)))
+}
@@ -0,0 +1,118 @@
+package rf
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModulation(t *testing.T) {
+
+	t.Run("Can calculate occupied bandwidth", func(t *testing.T) {
+		assert.InDelta(t, 1.2e+6, float64(OccupiedBandwidth(1e+6, 0.2)), allowedError)
+		assert.InDelta(t, 40.5e+6, float64(OccupiedBandwidth(30e+6, 0.35)), allowedError)
+		assert.InDelta(t, 1e+6, float64(OccupiedBandwidth(1e+6, 0)), allowedError)
+	})
+
+	t.Run("Can calculate symbol rate from bandwidth", func(t *testing.T) {
+		assert.InDelta(t, 30e+6, SymbolRateFromBandwidth(36*MHz, 0.2), allowedError)
+		assert.InDelta(t, 20e+6, SymbolRateFromBandwidth(27*MHz, 0.35), allowedError)
+
+		for _, rolloff := range []float64{0.2, 0.25, 0.35} {
+			assert.InDelta(t, 5e+6, SymbolRateFromBandwidth(OccupiedBandwidth(5e+6, rolloff), rolloff), allowedError)
+		}
+	})
+}
+
+func TestProcessingGain(t *testing.T) {
+
+	t.Run("Can calculate spread spectrum processing gain", func(t *testing.T) {
+		assert.InDelta(t, 10.41, float64(ProcessingGain(11e+6, 1e+6)), 0.01)
+		assert.InDelta(t, 0.0, float64(ProcessingGain(1e+6, 1e+6)), allowedError)
+	})
+
+	t.Run("LoRa processing gain matches published demodulator SNR limits", func(t *testing.T) {
+		// Semtech SX1276 datasheet demodulator SNR limits for SF7 through SF12
+		snrLimits := map[int]float64{7: -7.5, 8: -10, 9: -12.5, 10: -15, 11: -17.5, 12: -20}
+
+		for sf := 8; sf <= 12; sf++ {
+			improvement := float64(LoRaProcessingGain(sf) - LoRaProcessingGain(7))
+			assert.InDelta(t, snrLimits[7]-snrLimits[sf], improvement, 0.5, "SF%d", sf)
+		}
+
+		assert.InDelta(t, 12.62, float64(LoRaProcessingGain(7)), 0.01)
+		assert.InDelta(t, 25.33, float64(LoRaProcessingGain(12)), 0.01)
+	})
+}
+
+func TestBitErrorRate(t *testing.T) {
+
+	t.Run("Can calculate the Q function", func(t *testing.T) {
+		assert.InDelta(t, 0.5, QFunction(0), 1e-12)
+		assert.InDelta(t, 0.158655, QFunction(1), 1e-6)
+		assert.InDelta(t, 1.349898e-3, QFunction(3), 1e-9)
+	})
+
+	t.Run("BPSK matches published AWGN error rates", func(t *testing.T) {
+		tests := []struct {
+			ebN0, ber float64
+		}{
+			{0, 7.865e-2},
+			{4, 1.250e-2},
+			{6.8, 1e-3},
+			{9.6, 1e-5},
+		}
+
+		for _, test := range tests {
+			ber := BERAWGN(ModulationBPSK, test.ebN0)
+			assert.InDelta(t, test.ber, ber, test.ber*0.05, "Eb/N0 %.1fdB", test.ebN0)
+			assert.Equal(t, ber, BERAWGN(ModulationQPSK, test.ebN0))
+		}
+	})
+
+	t.Run("16-QAM matches published AWGN error rates", func(t *testing.T) {
+		assert.InDelta(t, 1.76e-3, BERAWGN(Modulation16QAM, 10), 0.01e-3)
+		assert.InDelta(t, 1e-5, BERAWGN(Modulation16QAM, 13.4), 0.1e-5)
+	})
+
+	t.Run("Higher order modulations require more Eb/N0", func(t *testing.T) {
+		schemes := []Modulation{ModulationBPSK, Modulation16QAM, Modulation64QAM, Modulation256QAM}
+		for i := 1; i < len(schemes); i++ {
+			assert.True(t, BERAWGN(schemes[i], 10) > BERAWGN(schemes[i-1], 10), "%s", schemes[i])
+		}
+
+		assert.True(t, BERAWGN(Modulation16PSK, 10) > BERAWGN(Modulation8PSK, 10))
+		assert.True(t, BERAWGN(Modulation8PSK, 10) > BERAWGN(ModulationQPSK, 10))
+	})
+
+	t.Run("Unknown modulations are not a number", func(t *testing.T) {
+		assert.True(t, math.IsNaN(BERAWGN("OOK", 10)))
+	})
+
+	t.Run("BPSK over Rayleigh fading matches the closed form", func(t *testing.T) {
+		for _, ebN0dB := range []float64{0, 10, 20} {
+			γ := math.Pow(10, ebN0dB/10)
+			assert.InDelta(t, 0.5*(1-math.Sqrt(γ/(1+γ))), BERRayleigh(ModulationBPSK, ebN0dB), 1e-12)
+		}
+		assert.InDelta(t, 0.146, BERRayleigh(ModulationBPSK, 0), 0.001)
+	})
+
+	t.Run("Rayleigh fading error rates fall as 1/SNR", func(t *testing.T) {
+		for _, scheme := range []Modulation{ModulationBPSK, Modulation8PSK, Modulation16QAM, Modulation64QAM} {
+			// Each 10dB of Eb/N0 reduces the error rate by a decade at high SNR
+			ratio := BERRayleigh(scheme, 30) / BERRayleigh(scheme, 40)
+			assert.InDelta(t, 10.0, ratio, 0.1, "%s", scheme)
+		}
+
+		// BPSK approaches 1/(4·Eb/N0)
+		assert.InDelta(t, 1/(4*1000.0), BERRayleigh(ModulationBPSK, 30), 1e-6)
+	})
+
+	t.Run("Rayleigh fading requires far more Eb/N0 than AWGN", func(t *testing.T) {
+		assert.True(t, BERRayleigh(ModulationBPSK, 10) > 100*BERAWGN(ModulationBPSK, 10))
+		assert.True(t, BERRayleigh(Modulation16QAM, 20) > BERAWGN(Modulation16QAM, 10))
+
+		assert.True(t, math.IsNaN(BERRayleigh("OOK", 10)))
+	})
+}
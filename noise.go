@@ -0,0 +1,158 @@
+package rf
+
+import (
+	"math"
+)
+
+// Noise and capacity calculations
+
+const (
+	// K is the Boltzmann constant in joules per kelvin
+	K = 1.380649e-23
+	// T0 is the standard reference noise temperature in kelvin
+	T0 = 290.0
+)
+
+// ThermalNoiseDBm calculates the thermal noise floor (kTB) in dBm over a bandwidth at the standard
+// reference temperature, including the receiver noise figure
+// https://en.wikipedia.org/wiki/Johnson%E2%80%93Nyquist_noise#Noise_power_in_decibels
+func ThermalNoiseDBm(bandwidthHz, noiseFigureDB float64) float64 {
+	return MilliWattToDecibelMilliVolt(K*T0*bandwidthHz*1000) + noiseFigureDB
+}
+
+// ShannonCapacity calculates the maximum achievable data rate (bits per second) of a channel
+// with the provided bandwidth and signal to noise ratio
+// https://en.wikipedia.org/wiki/Shannon%E2%80%93Hartley_theorem
+func ShannonCapacity(bandwidthHz, snrDB float64) float64 {
+	return bandwidthHz * math.Log2(1+math.Pow(10, snrDB/10))
+}
+
+// mimoCapacitySteps is the number of integration steps used when calculating MIMO capacity
+const mimoCapacitySteps = 20000
+
+// MIMOCapacity calculates the ergodic capacity (bits/s/Hz) of an i.i.d. Rayleigh fading MIMO channel with the
+// provided antenna counts and receive SNR, where the transmit power is split equally between the transmit antennas
+// without channel knowledge. This integrates Telatar's expression over the eigenvalue distribution of the channel,
+// with capacity growing approximately linearly with min(Nt, Nr). Returns NaN for invalid antenna counts.
+// https://en.wikipedia.org/wiki/MIMO#Mathematical_description
+func MIMOCapacity(txAntennas, rxAntennas int, snrDB float64) float64 {
+	if txAntennas < 1 || rxAntennas < 1 {
+		return math.NaN()
+	}
+
+	m, n := txAntennas, rxAntennas
+	if m > n {
+		m, n = n, m
+	}
+	α := float64(n - m)
+	ρ := math.Pow(10, snrDB/10)
+
+	// Unordered eigenvalue density of the Wishart matrix H·H* using generalized Laguerre polynomials, with the
+	// weight λ^α·e^-λ / (k!/(k+α)!) evaluated in log space to avoid overflow for large antenna counts
+	density := func(λ float64) float64 {
+		logWeight := -λ
+		if α > 0 {
+			logWeight += α * math.Log(λ)
+		}
+
+		sum := 0.0
+		prev, curr := 0.0, 1.0
+		for k := 0; k < m; k++ {
+			if k == 1 {
+				prev, curr = curr, 1+α-λ
+			} else if k > 1 {
+				prev, curr = curr, ((2*float64(k)-1+α-λ)*curr-(float64(k)-1+α)*prev)/float64(k)
+			}
+
+			kGamma, _ := math.Lgamma(float64(k) + 1)
+			knGamma, _ := math.Lgamma(float64(k) + α + 1)
+			sum += math.Exp(kGamma-knGamma+logWeight) * curr * curr
+		}
+		return sum / float64(m)
+	}
+
+	// Eigenvalues are concentrated below (√n + √m)²
+	limit := 4*math.Pow(math.Sqrt(float64(n))+math.Sqrt(float64(m)), 2) + 50
+
+	capacity := integrate(func(λ float64) float64 {
+		return math.Log2(1+ρ*λ/float64(txAntennas)) * density(λ)
+	}, 0, limit, mimoCapacitySteps)
+
+	return float64(m) * capacity
+}
+
+// IntegratedNoisePower calculates the total noise power in dBm between two frequencies by numerically integrating
+// k·T(f)·df over the provided number of steps, for receivers where the noise temperature varies with frequency
+func IntegratedNoisePower(noiseTempFn func(Frequency) float64, fLow, fHigh Frequency, steps int) float64 {
+	temperature := func(f float64) float64 { return noiseTempFn(Frequency(f)) }
+	power := K * integrate(temperature, float64(fLow), float64(fHigh), steps)
+
+	return MilliWattToDecibelMilliVolt(power * 1000)
+}
+
+// Sky noise model parameters
+const (
+	// skyNoiseCMB is the cosmic microwave background temperature in kelvin
+	skyNoiseCMB = 2.725
+	// skyNoiseGalactic408 is the typical cold sky galactic background temperature at 408MHz in kelvin
+	skyNoiseGalactic408 = 20.0
+	// skyNoiseGalacticIndex is the spectral index of galactic synchrotron emission
+	skyNoiseGalacticIndex = -2.75
+	// skyNoiseAtmosphere is the effective physical temperature of the absorbing atmosphere in kelvin
+	skyNoiseAtmosphere = 275.0
+)
+
+// AntennaNoiseTemperature estimates the sky noise temperature (in kelvin) seen by an antenna pointed at the
+// provided elevation above the horizon. This combines the cosmic background, a power law galactic background
+// (dominant at VHF and below) and atmospheric emission (dominant at low elevations and higher frequencies)
+// using an approximate zenith opacity with the Kasten-Young air mass. Ground spillover and sidelobes are
+// neglected. This is a rough approximation intended for frequencies between ~100MHz and ~30GHz.
+// https://en.wikipedia.org/wiki/Noise_temperature
+// https://en.wikipedia.org/wiki/Air_mass_(astronomy)#Interpolative_formulas
+func AntennaNoiseTemperature(elevationDeg float64, freq Frequency) float64 {
+	fGHz := float64(freq / GHz)
+
+	// Galactic and cosmic background
+	background := skyNoiseCMB + skyNoiseGalactic408*math.Pow(float64(freq/(408*MHz)), skyNoiseGalacticIndex)
+
+	// Approximate zenith opacity (nepers) rising with frequency due to oxygen and water vapour absorption
+	τZenith := 0.006 + 0.0001*fGHz*fGHz
+
+	// Kasten-Young air mass
+	z := 90 - math.Max(0, math.Min(90, elevationDeg))
+	airMass := 1 / (math.Cos(z/180*π) + 0.50572*math.Pow(96.07995-z, -1.6364))
+
+	τ := τZenith * airMass
+
+	return skyNoiseAtmosphere*(1-math.Exp(-τ)) + background*math.Exp(-τ)
+}
+
+// NoiseFigureToTemperature converts a noise figure in dB to an equivalent noise temperature in kelvin
+// https://en.wikipedia.org/wiki/Noise_figure#Noise_temperature
+func NoiseFigureToTemperature(noiseFigureDB float64) float64 {
+	return T0 * (math.Pow(10, noiseFigureDB/10) - 1)
+}
+
+// SystemNoiseTemperature calculates the system noise temperature in kelvin from the antenna noise
+// temperature and the receiver noise figure in dB
+func SystemNoiseTemperature(antennaNoiseTempK, noiseFigureDB float64) float64 {
+	return antennaNoiseTempK + NoiseFigureToTemperature(noiseFigureDB)
+}
+
+// GoverT calculates the receive system figure of merit G/T in dB/K from the antenna gain and system noise temperature
+// https://en.wikipedia.org/wiki/Antenna_gain-to-noise-temperature
+func GoverT(gainDBi float64, systemNoiseTempK float64) float64 {
+	return gainDBi - 10*math.Log10(systemNoiseTempK)
+}
+
+// ReceiverSensitivity calculates the minimum received power in dBm required to achieve the provided signal to
+// noise ratio over a bandwidth, given the receiver noise figure
+func ReceiverSensitivity(bandwidthHz, noiseFigureDB, requiredSNRdB float64) float64 {
+	return ThermalNoiseDBm(bandwidthHz, noiseFigureDB) + requiredSNRdB
+}
+
+// MinimumUsableSignal calculates the minimum received power in dBm required to achieve the provided signal to
+// noise ratio where the receiver is limited by the combination of the noise floor and interference (both in dBm)
+func MinimumUsableSignal(noiseFloorDBm, interferenceDBm, requiredSNRdB float64) float64 {
+	return AddDecibels(noiseFloorDBm, interferenceDBm) + requiredSNRdB
+}
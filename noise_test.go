@@ -0,0 +1,143 @@
+package rf
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoise(t *testing.T) {
+
+	t.Run("Can calculate the thermal noise floor", func(t *testing.T) {
+		assert.InDelta(t, -173.98, ThermalNoiseDBm(1, 0), 0.01)
+		assert.InDelta(t, -100.97, ThermalNoiseDBm(20e+6, 0), 0.01)
+		assert.InDelta(t, -94.97, ThermalNoiseDBm(20e+6, 6), 0.01)
+	})
+
+	t.Run("Can calculate Shannon capacity", func(t *testing.T) {
+		assert.InDelta(t, 1e+6, ShannonCapacity(1e+6, 0), allowedError)
+		assert.InDelta(t, 20e+6*3.45943, ShannonCapacity(20e+6, 10), 1e+3)
+	})
+
+	t.Run("Integrated noise power with constant temperature is kTB", func(t *testing.T) {
+		constant := func(f Frequency) float64 { return T0 }
+
+		power := IntegratedNoisePower(constant, 2.4*GHz, 2.42*GHz, 100)
+		assert.InDelta(t, ThermalNoiseDBm(20e+6, 0), power, allowedError)
+	})
+
+	t.Run("Integrated noise power follows frequency dependent temperature", func(t *testing.T) {
+		// Linearly increasing temperature averages to the midpoint temperature
+		linear := func(f Frequency) float64 { return 100 + 200*float64((f-100*MHz)/(10*MHz)) }
+
+		power := IntegratedNoisePower(linear, 100*MHz, 110*MHz, 10)
+		assert.InDelta(t, MilliWattToDecibelMilliVolt(K*200*10e+6*1000), power, allowedError)
+	})
+
+	t.Run("Antenna noise temperature is warmer at low elevations", func(t *testing.T) {
+		for _, f := range []Frequency{1 * GHz, 4 * GHz, 12 * GHz, 20 * GHz} {
+			zenith := AntennaNoiseTemperature(90, f)
+			low := AntennaNoiseTemperature(5, f)
+			horizon := AntennaNoiseTemperature(0, f)
+
+			assert.True(t, low > zenith, "frequency %.0f", float64(f))
+			assert.True(t, horizon > low, "frequency %.0f", float64(f))
+		}
+	})
+
+	t.Run("Antenna noise temperature follows expected magnitudes", func(t *testing.T) {
+		// Cool sky at zenith in the microwave window
+		assert.InDelta(t, 6.0, AntennaNoiseTemperature(90, 1*GHz), 2.0)
+		assert.InDelta(t, 5.0, AntennaNoiseTemperature(90, 4*GHz), 2.0)
+
+		// Warm atmosphere near the horizon
+		assert.True(t, AntennaNoiseTemperature(0, 12*GHz) > 100)
+
+		// Galactic noise dominates at VHF
+		assert.True(t, AntennaNoiseTemperature(90, 100*MHz) > 500)
+	})
+
+	t.Run("Can convert noise figures to noise temperatures", func(t *testing.T) {
+		assert.InDelta(t, 0.0, NoiseFigureToTemperature(0), allowedError)
+		assert.InDelta(t, 290.0, NoiseFigureToTemperature(3.0103), 0.01)
+		assert.InDelta(t, 58.66, NoiseFigureToTemperature(0.8), 0.01)
+	})
+
+	t.Run("Can calculate G/T for a VSAT terminal", func(t *testing.T) {
+		// 1.2m Ku band dish with 41.3dBi gain, 40K antenna temperature and 0.8dB LNB noise figure
+		tsys := SystemNoiseTemperature(40, 0.8)
+		assert.InDelta(t, 98.66, tsys, 0.01)
+
+		gt := GoverT(41.3, tsys)
+		assert.InDelta(t, 21.36, gt, 0.01)
+	})
+}
+
+func TestReceiverSensitivity(t *testing.T) {
+
+	t.Run("Can calculate receiver sensitivity", func(t *testing.T) {
+		// LoRa SF12 at 125kHz, -174 + 51 + 6 - 20
+		assert.InDelta(t, -137.0, ReceiverSensitivity(125e+3, 6, -20), 0.05)
+		assert.InDelta(t, ThermalNoiseDBm(1e+6, 3)+10, ReceiverSensitivity(1e+6, 3, 10), allowedError)
+	})
+}
+
+func TestMinimumUsableSignal(t *testing.T) {
+
+	t.Run("Interference dominates the noise floor", func(t *testing.T) {
+		assert.InDelta(t, -80+10, MinimumUsableSignal(-110, -80, 10), 0.01)
+	})
+
+	t.Run("Equal noise and interference raise the floor by 3dB", func(t *testing.T) {
+		assert.InDelta(t, -100+3.01+6, MinimumUsableSignal(-100, -100, 6), 0.01)
+	})
+}
+
+func TestMIMOCapacity(t *testing.T) {
+
+	t.Run("Single antenna capacity matches Rayleigh ergodic capacity", func(t *testing.T) {
+		// E[log2(1 + ρ|h|²)] = exp(1/ρ)·E1(1/ρ)/ln(2), 2.91 bits/s/Hz at 10dB
+		assert.InDelta(t, 2.91, MIMOCapacity(1, 1, 10), 0.01)
+
+		// Fading reduces the ergodic capacity below the AWGN Shannon capacity
+		assert.True(t, MIMOCapacity(1, 1, 10) < ShannonCapacity(1, 10))
+	})
+
+	t.Run("Capacity grows linearly with antenna count", func(t *testing.T) {
+		for _, snr := range []float64{0, 10, 20} {
+			single := MIMOCapacity(1, 1, snr)
+			for _, n := range []int{2, 4, 8} {
+				capacity := MIMOCapacity(n, n, snr)
+				assert.InDelta(t, float64(n)*single, capacity, float64(n)*single*0.1, "%dx%d at %.0fdB", n, n, snr)
+			}
+		}
+
+		assert.InDelta(t, 5.55, MIMOCapacity(2, 2, 10), 0.01)
+	})
+
+	t.Run("Capacity scales with the smaller antenna count", func(t *testing.T) {
+		// Additional antennas at one end only add array gain
+		assert.True(t, MIMOCapacity(2, 4, 10) < MIMOCapacity(4, 4, 10))
+		assert.True(t, MIMOCapacity(2, 4, 10) > MIMOCapacity(2, 2, 10))
+
+		// Transmit antennas without channel knowledge approach the AWGN capacity
+		assert.InDelta(t, ShannonCapacity(1, 10), MIMOCapacity(64, 1, 10), 0.05)
+	})
+
+	t.Run("Capacity is finite for large antenna counts", func(t *testing.T) {
+		// Large receive arrays harden the channel, approaching min(Nt, Nr)·log2(1 + ρ·Nr/Nt)
+		for _, test := range []struct{ tx, rx int }{{1, 128}, {1, 200}, {4, 200}} {
+			capacity := MIMOCapacity(test.tx, test.rx, 10)
+			hardened := float64(test.tx) * math.Log2(1+10*float64(test.rx)/float64(test.tx))
+
+			assert.False(t, math.IsNaN(capacity) || math.IsInf(capacity, 0), "%dx%d", test.tx, test.rx)
+			assert.True(t, capacity < hardened, "%dx%d", test.tx, test.rx)
+			assert.InDelta(t, hardened, capacity, 0.1, "%dx%d", test.tx, test.rx)
+		}
+	})
+
+	t.Run("Rejects invalid antenna counts", func(t *testing.T) {
+		assert.True(t, math.IsNaN(MIMOCapacity(0, 2, 10)))
+	})
+}
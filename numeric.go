@@ -0,0 +1,196 @@
+package rf
+
+import (
+	"fmt"
+	"math"
+)
+
+// Numerical helpers shared by the propagation models
+
+// Integrate numerically integrates f between a and b using the composite Simpson's rule over the provided
+// number of steps (rounded up to an even number)
+// https://en.wikipedia.org/wiki/Simpson%27s_rule#Composite_Simpson's_1/3_rule
+func Integrate(f func(float64) float64, a, b float64, steps int) float64 {
+	return integrate(f, a, b, steps)
+}
+
+// integrate implements the composite Simpson's rule for Integrate
+func integrate(f func(float64) float64, a, b float64, steps int) float64 {
+	if steps < 2 {
+		steps = 2
+	}
+	if steps%2 != 0 {
+		steps++
+	}
+
+	h := (b - a) / float64(steps)
+	sum := f(a) + f(b)
+	for i := 1; i < steps; i++ {
+		x := a + float64(i)*h
+		if i%2 == 0 {
+			sum += 2 * f(x)
+		} else {
+			sum += 4 * f(x)
+		}
+	}
+
+	return sum * h / 3
+}
+
+// SolverOptions configures the iterative root finders used by the inverse solvers
+type SolverOptions struct {
+	// MaxIterations is the maximum number of iterations before a solver fails to converge
+	MaxIterations int
+	// Tolerance is the absolute precision to which roots are resolved, in the units searched by each solver (see
+	// the WithOptions variants, such as MaxRangeForDataRateWithOptions)
+	Tolerance float64
+}
+
+// DefaultSolverOptions are the solver options used by the package inverse solvers (such as MaxRangeForDataRate),
+// the WithOptions variants (such as MaxRangeForDataRateWithOptions) trade speed for accuracy on a per-call basis
+var DefaultSolverOptions = SolverOptions{
+	MaxIterations: 1000,
+	Tolerance:     1e-9,
+}
+
+// validate checks solver options are usable
+func (o SolverOptions) validate() error {
+	if o.MaxIterations <= 0 {
+		return fmt.Errorf("Solver maximum iterations must be positive (iterations: %d)", o.MaxIterations)
+	}
+	if o.Tolerance <= 0 {
+		return fmt.Errorf("Solver tolerance must be positive (tolerance: %.4g)", o.Tolerance)
+	}
+	return nil
+}
+
+// Bisect finds a root of f between lo and hi to within the solver tolerance using the bisection method, where
+// f(lo) and f(hi) must have opposite signs. This is robust for any continuous function but converges linearly,
+// returning an error where the tolerance is not met within the maximum iterations.
+// https://en.wikipedia.org/wiki/Bisection_method
+func Bisect(f func(float64) float64, lo, hi float64, opts SolverOptions) (float64, error) {
+	if err := opts.validate(); err != nil {
+		return 0, err
+	}
+
+	flo, fhi := f(lo), f(hi)
+	if flo == 0 {
+		return lo, nil
+	}
+	if fhi == 0 {
+		return hi, nil
+	}
+	if math.Signbit(flo) == math.Signbit(fhi) {
+		return 0, fmt.Errorf("Root must be bracketed by lo and hi (f(%.4g): %.4g f(%.4g): %.4g)", lo, flo, hi, fhi)
+	}
+
+	for i := 0; math.Abs(hi-lo) > opts.Tolerance; i++ {
+		if i >= opts.MaxIterations {
+			return 0, fmt.Errorf("Bisection did not converge within %d iterations", opts.MaxIterations)
+		}
+
+		mid := (lo + hi) / 2
+		fmid := f(mid)
+		if fmid == 0 {
+			return mid, nil
+		}
+
+		if math.Signbit(fmid) == math.Signbit(flo) {
+			lo, flo = mid, fmid
+		} else {
+			hi = mid
+		}
+	}
+
+	return (lo + hi) / 2, nil
+}
+
+// Brent finds a root of f between lo and hi to within the solver tolerance using Brent's method, where f(lo) and
+// f(hi) must have opposite signs. This combines bisection with inverse quadratic interpolation, converging much
+// faster than Bisect for smooth functions while retaining its robustness, returning an error where the tolerance
+// is not met within the maximum iterations.
+// https://en.wikipedia.org/wiki/Brent%27s_method
+func Brent(f func(float64) float64, lo, hi float64, opts SolverOptions) (float64, error) {
+	if err := opts.validate(); err != nil {
+		return 0, err
+	}
+
+	a, b := lo, hi
+	fa, fb := f(a), f(b)
+	if fa == 0 {
+		return a, nil
+	}
+	if fb == 0 {
+		return b, nil
+	}
+	if math.Signbit(fa) == math.Signbit(fb) {
+		return 0, fmt.Errorf("Root must be bracketed by lo and hi (f(%.4g): %.4g f(%.4g): %.4g)", lo, fa, hi, fb)
+	}
+
+	c, fc := b, fb
+	d, e := 0.0, 0.0
+
+	for i := 0; i < opts.MaxIterations; i++ {
+		// Keep the root bracketed between b and c
+		if math.Signbit(fb) == math.Signbit(fc) {
+			c, fc = a, fa
+			d = b - a
+			e = d
+		}
+		// Keep b as the best estimate
+		if math.Abs(fc) < math.Abs(fb) {
+			a, b, c = b, c, b
+			fa, fb, fc = fb, fc, fb
+		}
+
+		tol1 := 2*epsilon*math.Abs(b) + opts.Tolerance/2
+		xm := (c - b) / 2
+		if math.Abs(xm) <= tol1 || fb == 0 {
+			return b, nil
+		}
+
+		if math.Abs(e) >= tol1 && math.Abs(fa) > math.Abs(fb) {
+			// Attempt inverse quadratic interpolation (or the secant method where only two points are known)
+			var p, q float64
+			s := fb / fa
+			if a == c {
+				p = 2 * xm * s
+				q = 1 - s
+			} else {
+				q = fa / fc
+				r := fb / fc
+				p = s * (2*xm*q*(q-r) - (b-a)*(r-1))
+				q = (q - 1) * (r - 1) * (s - 1)
+			}
+			if p > 0 {
+				q = -q
+			}
+			p = math.Abs(p)
+
+			// Accept the interpolation only where it falls within the bounds, otherwise bisect
+			if 2*p < math.Min(3*xm*q-math.Abs(tol1*q), math.Abs(e*q)) {
+				e = d
+				d = p / q
+			} else {
+				d = xm
+				e = d
+			}
+		} else {
+			d = xm
+			e = d
+		}
+
+		a, fa = b, fb
+		if math.Abs(d) > tol1 {
+			b += d
+		} else {
+			b += math.Copysign(tol1, xm)
+		}
+		fb = f(b)
+	}
+
+	return 0, fmt.Errorf("Root finding did not converge within %d iterations", opts.MaxIterations)
+}
+
+// epsilon is the float64 machine epsilon
+const epsilon = 2.220446049250313e-16
@@ -0,0 +1,111 @@
+package rf
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumeric(t *testing.T) {
+
+	t.Run("Integrates polynomials exactly", func(t *testing.T) {
+		// Simpson's rule is exact for cubics
+		cubic := func(x float64) float64 { return 2*x*x*x - x*x + 3 }
+		assert.InDelta(t, 2.0/4*16-8.0/3+6, Integrate(cubic, 0, 2, 2), 1e-12)
+		assert.InDelta(t, 1.0, Integrate(func(x float64) float64 { return 1 }, 0, 1, 10), 1e-12)
+	})
+
+	t.Run("Integrates analytic functions", func(t *testing.T) {
+		assert.InDelta(t, 2.0, Integrate(math.Sin, 0, math.Pi, 100), 1e-7)
+		assert.InDelta(t, math.E-1, Integrate(math.Exp, 0, 1, 100), 1e-9)
+		assert.InDelta(t, math.Log(10), Integrate(func(x float64) float64 { return 1 / x }, 1, 10, 1000), 1e-9)
+	})
+
+	t.Run("Rounds odd step counts up", func(t *testing.T) {
+		assert.InDelta(t, Integrate(math.Sin, 0, math.Pi, 102), Integrate(math.Sin, 0, math.Pi, 101), 1e-12)
+	})
+}
+
+func TestRootFinding(t *testing.T) {
+
+	finders := []struct {
+		name string
+		find func(f func(float64) float64, lo, hi float64, opts SolverOptions) (float64, error)
+	}{
+		{"Bisect", Bisect},
+		{"Brent", Brent},
+	}
+
+	for _, finder := range finders {
+		t.Run(finder.name+" finds roots of monotonic functions", func(t *testing.T) {
+			root, err := finder.find(func(x float64) float64 { return x*x - 2 }, 0, 2, DefaultSolverOptions)
+			assert.Nil(t, err)
+			assert.InDelta(t, math.Sqrt2, root, 1e-8)
+
+			root, err = finder.find(func(x float64) float64 { return x*x*x - 2*x - 5 }, 3, 2, DefaultSolverOptions)
+			assert.Nil(t, err)
+			assert.InDelta(t, 2.0945514815, root, 1e-8)
+		})
+
+		t.Run(finder.name+" finds roots of non-monotonic functions", func(t *testing.T) {
+			root, err := finder.find(math.Sin, 1, 4, DefaultSolverOptions)
+			assert.Nil(t, err)
+			assert.InDelta(t, math.Pi, root, 1e-8)
+
+			root, err = finder.find(math.Cos, 0, 2*math.Pi-2, DefaultSolverOptions)
+			assert.Nil(t, err)
+			assert.InDelta(t, math.Pi/2, root, 1e-8)
+		})
+
+		t.Run(finder.name+" requires a bracketed root", func(t *testing.T) {
+			_, err := finder.find(func(x float64) float64 { return x*x + 1 }, -1, 1, DefaultSolverOptions)
+			assert.NotNil(t, err)
+
+			root, err := finder.find(func(x float64) float64 { return x - 1 }, 1, 2, DefaultSolverOptions)
+			assert.Nil(t, err)
+			assert.InDelta(t, 1.0, root, allowedError)
+		})
+	}
+
+	t.Run("Brent converges faster than bisection for smooth functions", func(t *testing.T) {
+		bisectCalls, brentCalls := 0, 0
+
+		opts := SolverOptions{MaxIterations: 1000, Tolerance: 1e-12}
+
+		_, err := Bisect(func(x float64) float64 { bisectCalls++; return math.Exp(x) - 10 }, 0, 10, opts)
+		assert.Nil(t, err)
+		_, err = Brent(func(x float64) float64 { brentCalls++; return math.Exp(x) - 10 }, 0, 10, opts)
+		assert.Nil(t, err)
+
+		assert.True(t, brentCalls < bisectCalls, "brent: %d bisect: %d", brentCalls, bisectCalls)
+	})
+
+	for _, finder := range finders {
+		t.Run(finder.name+" requires more iterations for tighter tolerances", func(t *testing.T) {
+			f := func(x float64) float64 { return x*x*x - 2*x - 5 }
+
+			loose, tight := 0, 0
+			coarse, err := finder.find(func(x float64) float64 { loose++; return f(x) }, 2, 3, SolverOptions{MaxIterations: 100, Tolerance: 1e-3})
+			assert.Nil(t, err)
+			fine, err := finder.find(func(x float64) float64 { tight++; return f(x) }, 2, 3, SolverOptions{MaxIterations: 100, Tolerance: 1e-12})
+			assert.Nil(t, err)
+
+			assert.True(t, tight > loose, "tight: %d loose: %d", tight, loose)
+			assert.InDelta(t, 2.094551481542327, coarse, 1e-3)
+			assert.InDelta(t, 2.094551481542327, fine, 1e-11)
+		})
+
+		t.Run(finder.name+" fails to converge within too few iterations", func(t *testing.T) {
+			_, err := finder.find(math.Sin, 1, 4, SolverOptions{MaxIterations: 3, Tolerance: 1e-12})
+			assert.NotNil(t, err)
+		})
+
+		t.Run(finder.name+" rejects invalid options", func(t *testing.T) {
+			_, err := finder.find(math.Sin, 1, 4, SolverOptions{MaxIterations: 0, Tolerance: 1e-9})
+			assert.NotNil(t, err)
+			_, err = finder.find(math.Sin, 1, 4, SolverOptions{MaxIterations: 100, Tolerance: 0})
+			assert.NotNil(t, err)
+		})
+	}
+}
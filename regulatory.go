@@ -0,0 +1,112 @@
+package rf
+
+import (
+	"fmt"
+	"math"
+)
+
+// Regulatory band information
+// Note that limits are simplified for general planning, always check the regulations applicable to your deployment.
+// US: FCC 47 CFR Part 15.247
+// EU: ERC Recommendation 70-03, ETSI EN 300 220 / EN 300 328 / EN 300 440
+
+// Region is a regulatory region
+type Region string
+
+// Supported regulatory regions
+const (
+	RegionUS Region = "US"
+	RegionEU Region = "EU"
+)
+
+// BandInfo describes an unlicensed frequency band and its regulatory power limit in a region
+type BandInfo struct {
+	// Name is the common name of the band
+	Name string
+	// Region is the regulatory region the band information applies to
+	Region Region
+	// Min and Max are the band edges
+	Min, Max Frequency
+	// MaxEIRPDBm is the maximum Effective Isotropic Radiated Power in dBm
+	MaxEIRPDBm float64
+	// FieldStrengthLimit is the field strength limit in dBμV/m at FieldStrengthReferenceDistance,
+	// where zero the field strength limit is derived from MaxEIRPDBm
+	FieldStrengthLimit float64
+}
+
+// Bands is the registry of known unlicensed bands by region
+// ERP limits are converted to EIRP by adding the gain of a half-wave dipole
+// US limits are for digitally modulated devices under FCC 47 CFR Part 15.247, so field strength limits are derived
+// from the EIRP limit (the Part 15.249 field strength limits for general devices are a separate rule part)
+var Bands = []BandInfo{
+	{Name: "US 915MHz ISM", Region: RegionUS, Min: 902 * MHz, Max: 928 * MHz, MaxEIRPDBm: 36},
+	{Name: "US 2.4GHz ISM", Region: RegionUS, Min: 2400 * MHz, Max: 2483.5 * MHz, MaxEIRPDBm: 36},
+	{Name: "US 5.8GHz ISM", Region: RegionUS, Min: 5725 * MHz, Max: 5850 * MHz, MaxEIRPDBm: 36},
+
+	{Name: "EU 433MHz SRD", Region: RegionEU, Min: 433.05 * MHz, Max: 434.79 * MHz, MaxEIRPDBm: 10 + dBdToDBi},
+	{Name: "EU 868MHz SRD", Region: RegionEU, Min: 863 * MHz, Max: 870 * MHz, MaxEIRPDBm: 14 + dBdToDBi},
+	{Name: "EU 2.4GHz ISM", Region: RegionEU, Min: 2400 * MHz, Max: 2483.5 * MHz, MaxEIRPDBm: 20},
+	{Name: "EU 5.8GHz SRD", Region: RegionEU, Min: 5725 * MHz, Max: 5875 * MHz, MaxEIRPDBm: 14},
+}
+
+// LookupBand finds the unlicensed band containing a frequency in the provided region
+func LookupBand(freq Frequency, region Region) (BandInfo, error) {
+	for _, band := range Bands {
+		if band.Region == region && freq >= band.Min && freq <= band.Max {
+			return band, nil
+		}
+	}
+	return BandInfo{}, fmt.Errorf("No known band for frequency %.2fMHz in region %s", float64(freq/MHz), region)
+}
+
+// CheckEIRPCompliance calculates the EIRP of a transmitter from its power, antenna gain and feed losses
+// and checks it against the regulatory limit for the band containing the frequency in the provided region
+func CheckEIRPCompliance(txPowerDBm, gainDBi, lossDB float64, freq Frequency, region Region) (ok bool, limitDBm float64, err error) {
+	band, err := LookupBand(freq, region)
+	if err != nil {
+		return false, 0, err
+	}
+
+	eirp := txPowerDBm + gainDBi - lossDB
+
+	return eirp <= band.MaxEIRPDBm, band.MaxEIRPDBm, nil
+}
+
+// fieldStrengthConstant relates EIRP in dBm to field strength in dBμV/m at 1m, E = √(30·P)/d
+const fieldStrengthConstant = 104.77
+
+// FieldStrength calculates the far field strength in dBμV/m at a distance from a transmitter with the provided EIRP in dBm
+// https://en.wikipedia.org/wiki/Field_strength
+func FieldStrength(eirpDBm float64, distance Distance) float64 {
+	return eirpDBm - 20*math.Log10(float64(distance)) + fieldStrengthConstant
+}
+
+// EIRPFromFieldStrength calculates the EIRP in dBm of a transmitter producing the provided field strength in dBμV/m at a distance
+func EIRPFromFieldStrength(fieldDBuVm float64, distance Distance) float64 {
+	return fieldDBuVm + 20*math.Log10(float64(distance)) - fieldStrengthConstant
+}
+
+// FieldStrengthReferenceDistance is the measurement distance at which band field strength limits are defined
+const FieldStrengthReferenceDistance = 3 * M
+
+// CheckFieldStrengthMask predicts the field strength at a distance from a transmitter with the provided EIRP and
+// checks it against the field strength limit (in dBμV/m) at that distance for the band containing the frequency
+// in the provided region. Limits are scaled from FieldStrengthReferenceDistance assuming inverse distance (far field)
+// propagation, and are derived from the band EIRP limit where no field strength limit is specified.
+func CheckFieldStrengthMask(eirpDBm float64, freq Frequency, distance Distance, region Region) (ok bool, limit float64, err error) {
+	if distance <= 0 {
+		return false, 0, fmt.Errorf("Field strength distance must be positive (distance: %.2fm)", distance)
+	}
+
+	band, err := LookupBand(freq, region)
+	if err != nil {
+		return false, 0, err
+	}
+
+	limit = FieldStrength(band.MaxEIRPDBm, distance)
+	if band.FieldStrengthLimit != 0 {
+		limit = band.FieldStrengthLimit + 20*math.Log10(float64(FieldStrengthReferenceDistance/distance))
+	}
+
+	return FieldStrength(eirpDBm, distance) <= limit, limit, nil
+}
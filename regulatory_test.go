@@ -0,0 +1,138 @@
+package rf
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegulatory(t *testing.T) {
+
+	t.Run("2.4GHz EIRP limits differ between US and EU", func(t *testing.T) {
+		us, err := LookupBand(2.45*GHz, RegionUS)
+		assert.Nil(t, err)
+		assert.Equal(t, "US 2.4GHz ISM", us.Name)
+		assert.InDelta(t, 36.0, us.MaxEIRPDBm, allowedError)
+
+		eu, err := LookupBand(2.45*GHz, RegionEU)
+		assert.Nil(t, err)
+		assert.Equal(t, "EU 2.4GHz ISM", eu.Name)
+		assert.InDelta(t, 20.0, eu.MaxEIRPDBm, allowedError)
+	})
+
+	t.Run("Can lookup sub-GHz bands", func(t *testing.T) {
+		band, err := LookupBand(915*MHz, RegionUS)
+		assert.Nil(t, err)
+		assert.Equal(t, RegionUS, band.Region)
+
+		band, err = LookupBand(433.92*MHz, RegionEU)
+		assert.Nil(t, err)
+		assert.InDelta(t, 12.15, band.MaxEIRPDBm, allowedError)
+	})
+
+	t.Run("Band lookup fails outside of known bands", func(t *testing.T) {
+		_, err := LookupBand(915*MHz, RegionEU)
+		assert.NotNil(t, err)
+
+		_, err = LookupBand(2.5*GHz, RegionUS)
+		assert.NotNil(t, err)
+
+		_, err = LookupBand(2.45*GHz, Region("AU"))
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Can check EIRP compliance", func(t *testing.T) {
+		// 20dBm with a 6dBi antenna and 1dB feed loss is 25dBm EIRP
+		ok, limit, err := CheckEIRPCompliance(20, 6, 1, 2.45*GHz, RegionUS)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.InDelta(t, 36.0, limit, allowedError)
+
+		ok, limit, err = CheckEIRPCompliance(20, 6, 1, 2.45*GHz, RegionEU)
+		assert.Nil(t, err)
+		assert.False(t, ok)
+		assert.InDelta(t, 20.0, limit, allowedError)
+
+		// Exactly at the limit is compliant
+		ok, _, err = CheckEIRPCompliance(14, 6, 0, 2.45*GHz, RegionEU)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("EIRP compliance fails outside of known bands", func(t *testing.T) {
+		_, _, err := CheckEIRPCompliance(20, 6, 1, 3*GHz, RegionUS)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Can convert between EIRP and field strength", func(t *testing.T) {
+		// 1W EIRP produces 5.48V/m at 1m
+		assert.InDelta(t, 20*math.Log10(5.477e+6), FieldStrength(30, 1), 0.01)
+
+		// The field strength at 3m is EIRP + 95.23dB
+		assert.InDelta(t, 95.23, FieldStrength(0, 3), 0.01)
+		assert.InDelta(t, -95.23, EIRPFromFieldStrength(0, 3), 0.01)
+
+		// Field strength falls 20dB per decade
+		assert.InDelta(t, 20.0, FieldStrength(10, 3)-FieldStrength(10, 30), allowedError)
+
+		for _, eirp := range []float64{-10, 0, 14, 36} {
+			assert.InDelta(t, eirp, EIRPFromFieldStrength(FieldStrength(eirp, 10), 10), allowedError)
+		}
+	})
+
+	t.Run("Can check field strength masks", func(t *testing.T) {
+		// FCC Part 15.249 limits general 915MHz devices to 50mV/m (94dBμV/m) at 3m, ~-1.2dBm EIRP
+		general := BandInfo{Name: "US 915MHz 15.249", Region: "US-15.249", Min: 902 * MHz, Max: 928 * MHz, MaxEIRPDBm: -1.2, FieldStrengthLimit: 94}
+		Bands = append(Bands, general)
+		defer func() { Bands = Bands[:len(Bands)-1] }()
+
+		ok, limit, err := CheckFieldStrengthMask(-5, 915*MHz, 3, general.Region)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.InDelta(t, 94.0, limit, allowedError)
+
+		ok, limit, err = CheckFieldStrengthMask(0, 915*MHz, 3, general.Region)
+		assert.Nil(t, err)
+		assert.False(t, ok)
+		assert.InDelta(t, 94.0, limit, allowedError)
+
+		// Limits scale with measurement distance
+		_, limit, _ = CheckFieldStrengthMask(0, 915*MHz, 10, general.Region)
+		assert.InDelta(t, 94.0-10.46, limit, 0.01)
+	})
+
+	t.Run("US field strength masks match the EIRP limits", func(t *testing.T) {
+		for _, freq := range []Frequency{915 * MHz, 2.44 * GHz, 5.8 * GHz} {
+			ok, _, err := CheckEIRPCompliance(30, 6, 0, freq, RegionUS)
+			assert.Nil(t, err)
+			assert.True(t, ok)
+
+			ok, limit, err := CheckFieldStrengthMask(36, freq, 3, RegionUS)
+			assert.Nil(t, err)
+			assert.True(t, ok)
+			assert.InDelta(t, FieldStrength(36, 3), limit, allowedError)
+
+			ok, _, _ = CheckFieldStrengthMask(37, freq, 3, RegionUS)
+			assert.False(t, ok)
+		}
+	})
+
+	t.Run("Field strength masks derive from EIRP limits where not specified", func(t *testing.T) {
+		ok, limit, err := CheckFieldStrengthMask(16, 868*MHz, 3, RegionEU)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.InDelta(t, FieldStrength(14+dBdToDBi, 3), limit, allowedError)
+
+		ok, _, _ = CheckFieldStrengthMask(20, 868*MHz, 3, RegionEU)
+		assert.False(t, ok)
+	})
+
+	t.Run("Field strength masks fail outside of known bands", func(t *testing.T) {
+		_, _, err := CheckFieldStrengthMask(0, 100*MHz, 3, RegionUS)
+		assert.NotNil(t, err)
+
+		_, _, err = CheckFieldStrengthMask(0, 915*MHz, 0, RegionUS)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,136 @@
+package rf
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// Path analysis reports
+
+// ClearanceVerdict classifies the first fresnel zone clearance of a path
+type ClearanceVerdict string
+
+// Clearance verdicts based on the FresnelObstructionIdeal and FresnelObstructionOK limits
+const (
+	ClearanceIdeal      ClearanceVerdict = "ideal"
+	ClearanceOK         ClearanceVerdict = "ok"
+	ClearanceObstructed ClearanceVerdict = "obstructed"
+)
+
+// PathReport summarises the analysis of a terrain path between two points
+type PathReport struct {
+	// Frequency is the frequency used in the analysis
+	Frequency Frequency
+	// Distance is the line of sight distance between the endpoints
+	Distance Distance
+	// FreeSpaceLoss is the free space path loss over the line of sight distance
+	FreeSpaceLoss Attenuation
+	// DiffractionLoss is the loss due to the equivalent (Bullington) knife edge
+	DiffractionLoss Attenuation
+	// Impingement is the maximum proportion of the first fresnel zone obstructed by terrain
+	Impingement float64
+	// ImpingementPoint is the distance along the path of the maximum impingement
+	ImpingementPoint Distance
+	// Clearance is the fresnel zone clearance verdict for the path
+	Clearance ClearanceVerdict
+}
+
+// TotalLoss is the combined free space and diffraction loss over the path
+func (r PathReport) TotalLoss() Attenuation {
+	return r.FreeSpaceLoss + r.DiffractionLoss
+}
+
+// AnalyzePath analyses a terrain path between two points of set heights, combining the free space
+// path loss, Bullington Figure 12 diffraction loss and maximum fresnel zone impingement
+func AnalyzePath(p1, p2 float64, d Distance, f Frequency, terrain []float64) (PathReport, error) {
+	x, y, l := TerrainToPathXY(p1, p2, d, terrain)
+
+	fsl, err := CalculateFreeSpacePathLossChecked(f, Distance(l))
+	if err != nil {
+		return PathReport{}, err
+	}
+
+	// Diffraction loss is only defined where the approximation is valid, otherwise the
+	// equivalent knife edge is far enough below the line of sight to be neglected
+	d1, d2, h := BullingtonFigure12Method(x, y, Distance(l))
+	v, err := CalculateFresnelKirckoffDiffractionParam(f, d1, d2, Distance(h))
+	if err != nil {
+		return PathReport{}, err
+	}
+	if math.IsNaN(v) {
+		// Grazing paths produce a degenerate (flat) Bullington triangle with the edge on the line of sight
+		v = 0
+	}
+	diffraction := Attenuation(0)
+	if v >= -0.7 {
+		diffraction, err = CalculateFresnelKirchoffLossApprox(v)
+		if err != nil {
+			return PathReport{}, err
+		}
+	}
+
+	impingement, point := FresnelImpingementMax(x, y, Distance(l), f)
+
+	clearance := ClearanceObstructed
+	if impingement <= FresnelObstructionIdeal {
+		clearance = ClearanceIdeal
+	} else if impingement <= FresnelObstructionOK {
+		clearance = ClearanceOK
+	}
+
+	return PathReport{
+		Frequency:        f,
+		Distance:         Distance(l),
+		FreeSpaceLoss:    fsl,
+		DiffractionLoss:  diffraction,
+		Impingement:      impingement,
+		ImpingementPoint: point,
+		Clearance:        clearance,
+	}, nil
+}
+
+// pathReportJSON is the serialised form of a PathReport with units in field names
+type pathReportJSON struct {
+	FrequencyHz       float64          `json:"frequency_hz"`
+	DistanceM         float64          `json:"distance_m"`
+	FreeSpaceLossDB   float64          `json:"free_space_loss_db"`
+	DiffractionLossDB float64          `json:"diffraction_loss_db"`
+	TotalLossDB       float64          `json:"total_loss_db"`
+	Impingement       float64          `json:"fresnel_impingement"`
+	ImpingementPointM float64          `json:"impingement_point_m"`
+	Clearance         ClearanceVerdict `json:"clearance"`
+}
+
+// MarshalJSON serialises a PathReport with units preserved in the field names
+func (r PathReport) MarshalJSON() ([]byte, error) {
+	return json.Marshal(pathReportJSON{
+		FrequencyHz:       float64(r.Frequency),
+		DistanceM:         float64(r.Distance),
+		FreeSpaceLossDB:   float64(r.FreeSpaceLoss),
+		DiffractionLossDB: float64(r.DiffractionLoss),
+		TotalLossDB:       float64(r.TotalLoss()),
+		Impingement:       r.Impingement,
+		ImpingementPointM: float64(r.ImpingementPoint),
+		Clearance:         r.Clearance,
+	})
+}
+
+// UnmarshalJSON parses a PathReport serialised by MarshalJSON, the total loss is derived from the components
+func (r *PathReport) UnmarshalJSON(data []byte) error {
+	report := pathReportJSON{}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return err
+	}
+
+	*r = PathReport{
+		Frequency:        Frequency(report.FrequencyHz),
+		Distance:         Distance(report.DistanceM),
+		FreeSpaceLoss:    Attenuation(report.FreeSpaceLossDB),
+		DiffractionLoss:  Attenuation(report.DiffractionLossDB),
+		Impingement:      report.Impingement,
+		ImpingementPoint: Distance(report.ImpingementPointM),
+		Clearance:        report.Clearance,
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package rf
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathReport(t *testing.T) {
+
+	t.Run("Can analyze a terrain path", func(t *testing.T) {
+		report, err := AnalyzePath(0.0, 0.0, 50.0*M, 433*MHz, []float64{-100.0, -100.0, 0.0, -100.0, -100.0})
+		assert.Nil(t, err)
+
+		assert.InDelta(t, 50.0, float64(report.Distance), allowedError)
+		assert.InDelta(t, float64(CalculateFreeSpacePathLoss(433*MHz, 50*M)), float64(report.FreeSpaceLoss), allowedError)
+		assert.InDelta(t, 6.0, float64(report.DiffractionLoss), 0.1)
+		assert.InDelta(t, 0.5, report.Impingement, allowedError)
+		assert.InDelta(t, 25.0, float64(report.ImpingementPoint), allowedError)
+		assert.Equal(t, ClearanceObstructed, report.Clearance)
+	})
+
+	t.Run("Can analyze an obstructed terrain path", func(t *testing.T) {
+		report, err := AnalyzePath(0.0, 0.0, 50.0*M, 433*MHz, []float64{-100.0, -100.0, 1.0, -100.0, -100.0})
+		assert.Nil(t, err)
+
+		assert.InDelta(t, 10.13, float64(report.DiffractionLoss), 0.01)
+		assert.Equal(t, ClearanceObstructed, report.Clearance)
+	})
+
+	t.Run("Classifies clear paths", func(t *testing.T) {
+		report, err := AnalyzePath(0.0, 0.0, 50.0*M, 433*MHz, []float64{-100.0, -100.0, -100.0, -100.0, -100.0})
+		assert.Nil(t, err)
+
+		assert.InDelta(t, 0.0, float64(report.DiffractionLoss), allowedError)
+		assert.Equal(t, ClearanceIdeal, report.Clearance)
+	})
+
+	t.Run("Can round-trip a path report through JSON", func(t *testing.T) {
+		report, err := AnalyzePath(0.0, 0.0, 50.0*M, 433*MHz, []float64{-100.0, -100.0, 0.0, -100.0, -100.0})
+		assert.Nil(t, err)
+
+		data, err := json.Marshal(report)
+		assert.Nil(t, err)
+
+		fields := map[string]interface{}{}
+		err = json.Unmarshal(data, &fields)
+		assert.Nil(t, err)
+		assert.InDelta(t, 433e+6, fields["frequency_hz"], allowedError)
+		assert.InDelta(t, float64(report.TotalLoss()), fields["total_loss_db"], allowedError)
+		assert.Equal(t, "obstructed", fields["clearance"])
+
+		decoded := PathReport{}
+		err = json.Unmarshal(data, &decoded)
+		assert.Nil(t, err)
+		assert.Equal(t, report, decoded)
+	})
+}
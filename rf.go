@@ -19,7 +19,6 @@ package rf
 
 import (
 	"fmt"
-	"log"
 	"math"
 )
 
@@ -79,6 +78,52 @@ func CalculateFreeSpacePathLoss(freq Frequency, distance Distance) Attenuation {
 	return Attenuation(fading)
 }
 
+// FrequencyLossDelta calculates the additional free space path loss at f2 compared to f1 over the same distance,
+// ie. 20·log10(f2/f1), which is independent of the distance and negative where f2 is lower than f1
+func FrequencyLossDelta(f1, f2 Frequency, distance Distance) Attenuation {
+	return CalculateFreeSpacePathLoss(f2, distance) - CalculateFreeSpacePathLoss(f1, distance)
+}
+
+// IncidentPowerDensity calculates the far field power density (W/m²) at a distance from a transmitter with the
+// provided EIRP in dBm. Spreading loss is independent of frequency, which is accepted for symmetry with
+// PowerCapturedByAntenna, together forming the Friis transmission equation.
+// https://en.wikipedia.org/wiki/Friis_transmission_equation
+func IncidentPowerDensity(eirpDBm float64, distance Distance, freq Frequency) float64 {
+	watts := DecibelMilliVoltToMilliWatt(eirpDBm) / 1000
+	return watts / (4 * π * float64(distance*distance))
+}
+
+// PowerCapturedByAntenna calculates the power in dBm captured by an antenna with the provided gain from an incident
+// power density (W/m²), using the effective aperture of the antenna G·λ²/4π
+// https://en.wikipedia.org/wiki/Antenna_aperture
+func PowerCapturedByAntenna(densityWm2 float64, freq Frequency, gainDBi float64) float64 {
+	λ := float64(FrequencyToWavelength(freq))
+	aperture := math.Pow(10, gainDBi/10) * λ * λ / (4 * π)
+
+	return MilliWattToDecibelMilliVolt(densityWm2 * aperture * 1000)
+}
+
+// CalculateFreeSpacePathLossChecked calculates the Free Space Path Loss in Decibels for a given frequency and distance,
+// returning an error for zero or negative inputs where the formula is not meaningful
+func CalculateFreeSpacePathLossChecked(freq Frequency, distance Distance) (Attenuation, error) {
+	if freq <= 0 {
+		return 0, fmt.Errorf("Free space path loss requires a positive frequency (frequency: %.2fHz)", freq)
+	}
+
+	if distance <= 0 {
+		return 0, fmt.Errorf("Free space path loss requires a positive distance (distance: %.2fm)", distance)
+	}
+
+	return CalculateFreeSpacePathLoss(freq, distance), nil
+}
+
+// CalculateLogDistancePathLoss calculates path loss using the log-distance model, with free space loss to the
+// reference distance and the provided path loss exponent beyond it
+// https://en.wikipedia.org/wiki/Log-distance_path_loss_model
+func CalculateLogDistancePathLoss(freq Frequency, distance, reference Distance, exponent float64) Attenuation {
+	return CalculateFreeSpacePathLoss(freq, reference) + Attenuation(10*exponent*math.Log10(float64(distance/reference)))
+}
+
 // Freznel zone calculations
 // Note that distances must be much greater than wavelengths
 // https://en.wikipedia.org/wiki/Fresnel_zone#Fresnel_zone_clearance
@@ -90,9 +135,18 @@ const FresnelMinDistanceWavelengthRadio = 0.1
 // FresnelPoint calculates the fresnel zone radius d for a given wavelength
 // and order at a point P between endpoints
 func FresnelPoint(d1, d2 Distance, freq Frequency, order int64) (float64, error) {
-	wavelength := FrequencyToWavelength(freq)
+	return FresnelPointWithTolerance(d1, d2, freq, order, FresnelMinDistanceWavelengthRadio)
+}
+
+// FresnelPointWithTolerance calculates the fresnel zone radius as FresnelPoint, using the provided tolerance
+// in place of FresnelMinDistanceWavelengthRadio. Larger tolerances permit distances closer to the wavelength.
+func FresnelPointWithTolerance(d1, d2 Distance, freq Frequency, order int64, tolerance float64) (float64, error) {
+	wavelength, err := FrequencyToWavelengthChecked(freq)
+	if err != nil {
+		return 0, err
+	}
 
-	if ((float64(d1) * FresnelMinDistanceWavelengthRadio) < float64(wavelength)) || ((float64(d2) * FresnelMinDistanceWavelengthRadio) < float64(wavelength)) {
+	if ((float64(d1) * tolerance) < float64(wavelength)) || ((float64(d2) * tolerance) < float64(wavelength)) {
 		return 0, fmt.Errorf("Fresnel calculation valid only for distances >> wavelength (d1: %.2fm d2: %.2fm wavelength %.2fm)", d1, d2, wavelength)
 	}
 
@@ -101,22 +155,63 @@ func FresnelPoint(d1, d2 Distance, freq Frequency, order int64) (float64, error)
 
 // FresnelFirstZoneMax calculates the maximum fresnel zone radius for a given frequency
 func FresnelFirstZoneMax(freq Frequency, dist Distance) (float64, error) {
+	return FresnelFirstZoneMaxWithTolerance(freq, dist, FresnelMinDistanceWavelengthRadio)
+}
+
+// FresnelFirstZoneMaxWithTolerance calculates the maximum fresnel zone radius as FresnelFirstZoneMax, using the provided
+// tolerance in place of FresnelMinDistanceWavelengthRadio. Larger tolerances permit distances closer to the wavelength.
+func FresnelFirstZoneMaxWithTolerance(freq Frequency, dist Distance, tolerance float64) (float64, error) {
 
-	wavelength := FrequencyToWavelength(freq)
-	if (float64(dist) * FresnelMinDistanceWavelengthRadio) < float64(wavelength) {
+	wavelength, err := FrequencyToWavelengthChecked(freq)
+	if err != nil {
+		return 0, err
+	}
+	if (float64(dist) * tolerance) < float64(wavelength) {
 		return 0, fmt.Errorf("Fresnel calculation valid only for distance >> wavelength (distance: %.2fm wavelength %.2fm)", dist, wavelength)
 	}
 
 	return 0.5 * math.Sqrt((C * float64(dist) / float64(freq))), nil
 }
 
+// FresnelEllipsoidRadiusAt calculates the radius of a fresnel zone at a fractional position (0 to 1) along a path,
+// with the zone radius falling to zero at the endpoints
+func FresnelEllipsoidRadiusAt(fraction float64, totalDist Distance, freq Frequency, zone int) (float64, error) {
+	if fraction < 0 || fraction > 1 {
+		return 0, fmt.Errorf("Fresnel zone position must be a fraction between 0 and 1 (fraction: %.2f)", fraction)
+	}
+	if zone < 1 {
+		return 0, fmt.Errorf("Fresnel zone must be 1 or greater (zone: %d)", zone)
+	}
+	if fraction == 0 || fraction == 1 {
+		return 0, nil
+	}
+
+	d1 := totalDist * Distance(fraction)
+	return FresnelPoint(d1, totalDist-d1, freq, int64(zone))
+}
+
+// FresnelEllipsoidVolume calculates the volume enclosed by a fresnel zone, an ellipsoid with the path endpoints
+// as foci and a path length difference of zone·λ/2
+func FresnelEllipsoidVolume(totalDist Distance, freq Frequency, zone int) float64 {
+	wavelength := float64(FrequencyToWavelength(freq))
+
+	// Semi-major axis and focal distance
+	a := (float64(totalDist) + float64(zone)*wavelength/2) / 2
+	c := float64(totalDist) / 2
+
+	return 4.0 / 3 * π * a * (a*a - c*c)
+}
+
 // CalculateFresnelKirckoffDiffractionParam Calculates the Fresnel-Kirchoff Diffraction parameter
 // d1 and d2 are the distances between the "knife edge" impingement and the transmitter/receiver
 // h is the impingement, where -ve is below Line of Sight (LoS) and +ve is above LoS
 // https://en.wikipedia.org/wiki/Kirchhoff%27s_diffraction_formula
 // https://s.campbellsci.com/documents/au/technical-papers/line-of-sight-obstruction.pdf
 func CalculateFresnelKirckoffDiffractionParam(freq Frequency, d1, d2, h Distance) (v float64, err error) {
-	wavelength := FrequencyToWavelength(freq)
+	wavelength, err := FrequencyToWavelengthChecked(freq)
+	if err != nil {
+		return 0, err
+	}
 	v = float64(h) * math.Sqrt((2*float64(d1+d2))/(float64(wavelength)*float64(d1*d2)))
 	return v, err
 }
@@ -132,6 +227,71 @@ func CalculateFresnelKirchoffLossApprox(v float64) (Attenuation, error) {
 	return Attenuation(loss), nil
 }
 
+// RoundedObstacleLoss calculates the diffraction loss over a rounded obstacle (such as a broad hilltop) with the
+// provided radius of curvature, adding the ITU-R P.526 rounded obstacle correction T(m,n) to the knife edge loss
+// from CalculateFresnelKirchoffLossApprox. d1, d2 and h are as for CalculateFresnelKirckoffDiffractionParam.
+// https://www.itu.int/rec/R-REC-P.526
+func RoundedObstacleLoss(freq Frequency, d1, d2 Distance, h Distance, radiusOfCurvature Distance) (Attenuation, error) {
+	if radiusOfCurvature <= 0 {
+		return 0, fmt.Errorf("Radius of curvature must be positive (radius: %.2fm)", radiusOfCurvature)
+	}
+
+	v, err := CalculateFresnelKirckoffDiffractionParam(freq, d1, d2, h)
+	if err != nil {
+		return 0, err
+	}
+
+	knifeEdge, err := CalculateFresnelKirchoffLossApprox(v)
+	if err != nil {
+		return 0, err
+	}
+
+	λ := float64(FrequencyToWavelength(freq))
+	r := float64(radiusOfCurvature)
+	scale := π * r / λ
+
+	// Curvature (m) and height (n) indices of the obstacle
+	m := r * float64(d1+d2) / float64(d1*d2) / math.Cbrt(scale)
+	n := float64(h) * math.Pow(scale, 2.0/3) / r
+
+	// Correction T(m,n), with the power law form valid for mn <= 4
+	var t float64
+	if m*n <= 4 {
+		k := 8.2 + 12.0*n
+		b := 0.73 + 0.27*(1-math.Exp(-1.43*n))
+		t = k * math.Pow(m, b)
+	} else {
+		t = -6 - 20*math.Log10(m*n) + 7.2*math.Sqrt(m) - (2-17*n)*m + 3.6*math.Pow(m, 1.5) - 0.8*m*m
+	}
+
+	// The correction is not applied to reduce the loss of paths clearing the obstacle (n < 0)
+	return knifeEdge + Attenuation(math.Max(0, t)), nil
+}
+
+// SingleScreenDiffraction calculates the diffraction loss over a single screen (such as a building rooftop or mast)
+// at distances dTx and dRx from the transmitter and receiver, with screen and antenna heights in meters in the same
+// datum, using CalculateFresnelKirchoffLossApprox. Screens far enough below the line of sight to be outside the
+// validity of the approximation (v < -0.7) cause negligible loss, and zero is returned.
+func SingleScreenDiffraction(freq Frequency, dTx, dRx Distance, screenHeight, txHeight, rxHeight float64) (Attenuation, error) {
+	if dTx <= 0 || dRx <= 0 {
+		return 0, fmt.Errorf("Screen distances must be positive (dTx: %.2fm dRx: %.2fm)", dTx, dRx)
+	}
+
+	// Height of the screen above the line of sight
+	los := txHeight + (rxHeight-txHeight)*float64(dTx/(dTx+dRx))
+	h := Distance(screenHeight - los)
+
+	v, err := CalculateFresnelKirckoffDiffractionParam(freq, dTx, dRx, h)
+	if err != nil {
+		return 0, err
+	}
+	if v < -0.7 {
+		return 0, nil
+	}
+
+	return CalculateFresnelKirchoffLossApprox(v)
+}
+
 const (
 	WeissbergerMinFreq = 230 * MHz
 	WeissbergerMaxFreq = 95 * GHz
@@ -142,45 +302,73 @@ const (
 // CalculateFoliageLoss calculates path loss in dB due to foliage based on the Weissberger model
 // https://en.wikipedia.org/wiki/Weissberger%27s_model
 func CalculateFoliageLoss(freq Frequency, depth Distance) (Attenuation, error) {
+	if depth <= WeissbergerMinDist || depth > WeissbergerMaxDist {
+		return 0, fmt.Errorf("Depth %.2f is not between 0 and 400m as required by the Weissberger model", depth)
+	}
+
+	return weissbergerLoss(freq, depth)
+}
+
+// CalculateFoliageLossExtrapolated calculates path loss in dB due to foliage based on the Weissberger model,
+// extrapolating the model beyond WeissbergerMaxDist for deeper vegetation. The returned flag is set where the
+// result is an extrapolation outside the validated range of the model.
+func CalculateFoliageLossExtrapolated(freq Frequency, depth Distance) (Attenuation, bool, error) {
+	if depth <= WeissbergerMaxDist {
+		loss, err := CalculateFoliageLoss(freq, depth)
+		return loss, false, err
+	}
+
+	loss, err := weissbergerLoss(freq, depth)
+	return loss, err == nil, err
+}
+
+// weissbergerLoss calculates the Weissberger foliage loss for a positive depth, applying the deep vegetation
+// formula beyond 14m without limiting the depth
+func weissbergerLoss(freq Frequency, depth Distance) (Attenuation, error) {
 	if freq < WeissbergerMinFreq || freq > WeissbergerMaxFreq {
 		return 0, fmt.Errorf("Frequency %.2f is not between 230MHz and 95GHz as required by the Weissberger model", freq)
 	}
 
-	if depth < WeissbergerMinDist || depth > WeissbergerMaxDist {
-		return 0, fmt.Errorf("Depth %.2f is not between 0 and 400m as required by the Weissberger model", depth)
+	if depth <= 14.0 {
+		return Attenuation(0.45 * math.Pow(float64(freq/GHz), 0.284) * float64(depth)), nil
 	}
 
-	fading := 0.0
-	if depth > 0.0 && depth <= 14.0 {
-		fading = 0.45 * math.Pow(float64(freq/GHz), 0.284) * float64(depth)
-	} else if depth > 14.0 && depth <= 400.0 {
-		fading = 1.33 * math.Pow(float64(freq/GHz), 0.284) * math.Pow(float64(depth), 0.588)
-	} else {
-		return 0, fmt.Errorf("Depth %.2f is not between 0 and 400m as required by the Weissberger model", depth)
+	return Attenuation(1.33 * math.Pow(float64(freq/GHz), 0.284) * math.Pow(float64(depth), 0.588)), nil
+}
+
+// CalculateFoliageLossP833 calculates the excess path loss in dB due to foliage using the ITU-R P.833 exponential
+// model, which saturates at maxLossDB for deep vegetation. The specific attenuation of the vegetation is approximated
+// by the (short depth) Weissberger rate of 0.45·f^0.284 dB/m with f in GHz.
+// https://www.itu.int/rec/R-REC-P.833
+func CalculateFoliageLossP833(freq Frequency, depth Distance, maxLossDB float64) Attenuation {
+	if depth <= 0 || maxLossDB <= 0 {
+		return 0
 	}
 
-	return Attenuation(fading), nil
-}
+	γ := 0.45 * math.Pow(float64(freq/GHz), 0.284)
 
-// CalculateRaleighFading calculates Raleigh fading
-// https://en.wikipedia.org/wiki/Rayleigh_fading
-func CalculateRaleighFading(freq Frequency) (Attenuation, error) {
-	log.Panicf("Raleigh fading not yet implemented")
-	return 0.0, nil
+	return Attenuation(maxLossDB * (1 - math.Exp(-γ*float64(depth)/maxLossDB)))
 }
 
-// CalculateRicanFading calculates Rican fading
-// https://en.wikipedia.org/wiki/Rician_fading
-func CalculateRicanFading(freq Frequency) (Attenuation, error) {
-	log.Panicf("Rican fading not yet implemented")
-	return 0.0, nil
-}
+// CalculatePathWithFoliage calculates the combined free space and foliage loss over a path of totalDistance
+// passing through foliageDepth of vegetation, using CalculateFreeSpacePathLoss and CalculateFoliageLoss.
+// Paths without foliage (zero depth) return the free space loss.
+func CalculatePathWithFoliage(freq Frequency, totalDistance, foliageDepth Distance) (Attenuation, error) {
+	if foliageDepth > totalDistance {
+		return 0, fmt.Errorf("Foliage depth %.2fm exceeds path distance %.2fm", foliageDepth, totalDistance)
+	}
+
+	freeSpace := CalculateFreeSpacePathLoss(freq, totalDistance)
+	if foliageDepth == 0 {
+		return freeSpace, nil
+	}
 
-// CalculateWeibullFading calculates Weibull fading
-// https://en.wikipedia.org/wiki/Weibull_fading
-func CalculateWeibullFading(freq Frequency) (Attenuation, error) {
-	log.Panicf("Weibull fading not yet implemented")
-	return 0.0, nil
+	foliage, err := CalculateFoliageLoss(freq, foliageDepth)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid foliage for path: %w", err)
+	}
+
+	return freeSpace + foliage, nil
 }
 
 // BullingtonFigure12Method implements the Bullington Figure 12 (intersecting horizons) method to approximate
@@ -230,16 +418,42 @@ func solveBullingtonFigureTwelveDist(θb, θc float64, d Distance) (dist Distanc
 
 // FresnelImpingementMax computes the maximum first fresnel zone impingement due to terrain between two points
 func FresnelImpingementMax(x, y []float64, d Distance, f Frequency) (maxImpingement float64, point Distance) {
-	maxImpingement, point = 0.0, d/2
+	return FresnelImpingementMaxWithTolerance(x, y, d, f, FresnelMinDistanceWavelengthRadio)
+}
+
+// FresnelImpingementMaxWithTolerance computes the maximum first fresnel zone impingement as FresnelImpingementMax, using
+// the provided tolerance in place of FresnelMinDistanceWavelengthRadio. Larger tolerances permit analysis of points
+// closer to the endpoints (in wavelengths), at the cost of accuracy.
+func FresnelImpingementMaxWithTolerance(x, y []float64, d Distance, f Frequency, tolerance float64) (maxImpingement float64, point Distance) {
+	maxImpingement, point, _, _ = fresnelImpingementMax(x, y, d, f, tolerance)
+	return maxImpingement, point
+}
+
+// FresnelImpingementMaxChecked computes the maximum first fresnel zone impingement as FresnelImpingementMax, additionally
+// returning the number of points skipped where the fresnel calculation is invalid (wavelength is not << d1 or d2).
+// An error is returned alongside the partial result where any points were skipped, as the result may be unreliable.
+func FresnelImpingementMaxChecked(x, y []float64, d Distance, f Frequency) (maxImpingement float64, point Distance, skipped int, err error) {
+	maxImpingement, point, _, skipped = fresnelImpingementMax(x, y, d, f, FresnelMinDistanceWavelengthRadio)
+	if skipped > 0 {
+		err = fmt.Errorf("Fresnel impingement skipped %d of %d points where distance is not >> wavelength", skipped, len(x)-2)
+	}
+	return maxImpingement, point, skipped, err
+}
+
+// fresnelImpingementMax computes the maximum first fresnel zone impingement, the index of the point at which it occurs
+// (-1 where there is no impingement) and the number of points skipped
+func fresnelImpingementMax(x, y []float64, d Distance, f Frequency, tolerance float64) (maxImpingement float64, point Distance, index int, skipped int) {
+	maxImpingement, point, index = 0.0, d/2, -1
 
 	for i := 1; i < len(x)-1; i++ {
 		d1 := Distance(x[i])
 		d2 := Distance(d) - d1
 
 		// Calculate size of fresnel zone
-		fresnelZone, err := FresnelPoint(d1, d2, f, 1)
+		fresnelZone, err := FresnelPointWithTolerance(d1, d2, f, 1, tolerance)
 		if err != nil {
 			// Skip invalid points (where wavelength is not << d1 or d2)
+			skipped++
 			continue
 		}
 
@@ -257,8 +471,57 @@ func FresnelImpingementMax(x, y []float64, d Distance, f Frequency) (maxImpingem
 		if impingement > maxImpingement {
 			maxImpingement = impingement
 			point = d1
+			index = i
 		}
 	}
 
-	return maxImpingement, point
+	return maxImpingement, point, index, skipped
+}
+
+// fresnelClearanceMin computes the minimum first fresnel zone clearance due to terrain between two points, as the ratio
+// of the distance of terrain below the line of sight to the first fresnel zone radius (ie. 0.6 meets the 60% rule, and
+// negative values are above the line of sight), returning the point at which it occurs and the number of points skipped
+// where the fresnel calculation is invalid. Clearance is +Inf where no points could be evaluated.
+func fresnelClearanceMin(x, y []float64, d Distance, f Frequency) (minClearance float64, point Distance, skipped int) {
+	minClearance, point = math.Inf(1), d/2
+
+	for i := 1; i < len(x)-1; i++ {
+		d1 := Distance(x[i])
+		d2 := Distance(d) - d1
+
+		radius, err := FresnelPoint(d1, d2, f, 1)
+		if err != nil {
+			skipped++
+			continue
+		}
+
+		if clearance := -y[i] / radius; clearance < minClearance {
+			minClearance, point = clearance, d1
+		}
+	}
+
+	return minClearance, point, skipped
+}
+
+// fresnelClearanceDeficit computes the largest distance (in the units of y) by which terrain between two points falls
+// short of clearing the provided fraction of the first fresnel zone radius, as fresnelClearanceMin, returning the
+// number of points skipped where the fresnel calculation is invalid. The deficit is -Inf where no points could be
+// evaluated, and negative where every point is clear.
+func fresnelClearanceDeficit(x, y []float64, d Distance, f Frequency, fraction float64) (maxDeficit float64, skipped int) {
+	maxDeficit = math.Inf(-1)
+
+	for i := 1; i < len(x)-1; i++ {
+		d1 := Distance(x[i])
+		d2 := Distance(d) - d1
+
+		radius, err := FresnelPoint(d1, d2, f, 1)
+		if err != nil {
+			skipped++
+			continue
+		}
+
+		maxDeficit = math.Max(maxDeficit, fraction*radius+y[i])
+	}
+
+	return maxDeficit, skipped
 }
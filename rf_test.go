@@ -1,9 +1,13 @@
 package rf
 
 import (
+	"bytes"
+	"encoding/csv"
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"math"
+	"strconv"
 	"testing"
 )
 
@@ -42,6 +46,14 @@ func TestRFUtils(t *testing.T) {
 		assert.InDelta(t, -20, dbm, allowedError)
 	})
 
+	t.Run("Can add power decibels", func(t *testing.T) {
+		assert.InDelta(t, 3.01, AddDecibels(0, 0), 0.01)
+		assert.InDelta(t, -90+4.77, AddDecibels(-90, -90, -90), 0.01)
+		assert.InDelta(t, -60.0, AddDecibels(-60, -120), 0.01)
+		assert.InDelta(t, -100.0, AddDecibels(-100), allowedError)
+		assert.True(t, math.IsInf(AddDecibels(), -1))
+	})
+
 	t.Run("Can calculate free space attenuation", func(t *testing.T) {
 
 		// Test against precalculated results
@@ -62,6 +74,61 @@ func TestRFUtils(t *testing.T) {
 		assert.InDelta(t, 145.178, float64(dBLoss), allowedError)
 	})
 
+	t.Run("Can compare free space attenuation between frequencies", func(t *testing.T) {
+		assert.InDelta(t, 6.375, float64(FrequencyLossDelta(2.4*GHz, 5*GHz, 1*Km)), allowedError)
+		assert.InDelta(t, -6.375, float64(FrequencyLossDelta(5*GHz, 2.4*GHz, 1*Km)), allowedError)
+
+		// The difference is independent of distance
+		for _, d := range []Distance{10 * M, 1 * Km, 100 * Km} {
+			assert.InDelta(t, 20*math.Log10(2.4e+9/433e+6), float64(FrequencyLossDelta(433*MHz, 2.4*GHz, d)), allowedError)
+		}
+	})
+
+	t.Run("Can calculate incident power density", func(t *testing.T) {
+		// 1W over a 1m radius sphere
+		assert.InDelta(t, 1/(4*math.Pi), IncidentPowerDensity(30, 1*M, 2.4*GHz), 1e-9)
+		assert.InDelta(t, 1/(4*math.Pi)/1e+6, IncidentPowerDensity(30, 1*Km, 2.4*GHz), 1e-15)
+	})
+
+	t.Run("Incident power density and captured power compose to the Friis equation", func(t *testing.T) {
+		for _, f := range []Frequency{433 * MHz, 2.4 * GHz, 5.8 * GHz} {
+			for _, d := range []Distance{100 * M, 1 * Km, 10 * Km} {
+				density := IncidentPowerDensity(20, d, f)
+				captured := PowerCapturedByAntenna(density, f, 6)
+
+				friis := 20 + 6 - float64(CalculateFreeSpacePathLoss(f, d))
+				assert.InDelta(t, friis, captured, allowedError, "%.0fMHz at %.0fm", f/MHz, d)
+			}
+		}
+	})
+
+	t.Run("Can calculate log-distance attenuation", func(t *testing.T) {
+		// Free space exponent matches free space loss
+		dBLoss := CalculateLogDistancePathLoss(2.4*GHz, 1e+3, 1, 2)
+		assert.InDelta(t, 100.05, float64(dBLoss), allowedError)
+
+		dBLoss = CalculateLogDistancePathLoss(2.4*GHz, 1e+2, 1, 3)
+		assert.InDelta(t, 100.05, float64(dBLoss), allowedError)
+	})
+
+	t.Run("Checked free space attenuation rejects degenerate inputs", func(t *testing.T) {
+		dBLoss, err := CalculateFreeSpacePathLossChecked(2.4*GHz, 1e+3)
+		assert.Nil(t, err)
+		assert.InDelta(t, 100.05, float64(dBLoss), allowedError)
+
+		_, err = CalculateFreeSpacePathLossChecked(2.4*GHz, 0)
+		assert.NotNil(t, err)
+
+		_, err = CalculateFreeSpacePathLossChecked(2.4*GHz, -1e+3)
+		assert.NotNil(t, err)
+
+		_, err = CalculateFreeSpacePathLossChecked(0, 1e+3)
+		assert.NotNil(t, err)
+
+		_, err = CalculateFreeSpacePathLossChecked(-433*MHz, 1e+3)
+		assert.NotNil(t, err)
+	})
+
 	t.Run("Can calculate the distance between two lat/lon locations", func(t *testing.T) {
 		lat1, lon1 := -36.8485, 174.7633
 		lat2, lon2 := -41.2865, 174.7762
@@ -70,6 +137,92 @@ func TestRFUtils(t *testing.T) {
 		assert.InDelta(t, 493.4e+3, float64(d), 1e+3)
 	})
 
+	t.Run("Can convert heights to wavelengths", func(t *testing.T) {
+		assert.InDelta(t, 80.05, HeightInWavelengths(10, 2.4*GHz), 0.01)
+		assert.InDelta(t, 4.33, HeightInWavelengths(3, 433*MHz), 0.01)
+		assert.InDelta(t, 1.0, HeightInWavelengths(float64(FrequencyToWavelength(900*MHz)), 900*MHz), allowedError)
+	})
+
+	t.Run("Can calculate propagation delays", func(t *testing.T) {
+		assert.InDelta(t, 3.3356e-6, PropagationDelay(1*Km), 1e-9)
+		assert.InDelta(t, 6.6711e-6, RoundTripDelay(1*Km), 1e-9)
+
+		// Geostationary slant range at the sub-satellite point
+		assert.InDelta(t, 0.11937, PropagationDelay(35786*Km), 1e-5)
+		assert.InDelta(t, 0.23873, RoundTripDelay(35786*Km), 1e-5)
+	})
+
+	t.Run("Can calculate phase shifts over distance", func(t *testing.T) {
+		λ := float64(FrequencyToWavelength(2.4 * GHz))
+
+		assert.InDelta(t, math.Pi, PhaseShift(Distance(λ/2), 2.4*GHz), allowedError)
+		assert.InDelta(t, 2*math.Pi, PhaseShift(Distance(λ), 2.4*GHz), allowedError)
+
+		assert.InDelta(t, math.Pi, PhaseDifference(Distance(10+λ/2), 10, 2.4*GHz), allowedError)
+		assert.InDelta(t, -2*math.Pi, PhaseDifference(10, Distance(10+λ), 2.4*GHz), allowedError)
+	})
+
+	t.Run("Can validate terrain profile lengths", func(t *testing.T) {
+		lat1, lon1 := -36.8485, 174.7633
+		lat2, lon2 := -36.7485, 174.8633
+		d := CalculateDistance(lat1, lon1, lat2, lon2, R)
+
+		assert.Nil(t, ValidateProfileLength(lat1, lon1, lat2, lon2, d, 0.01))
+		assert.Nil(t, ValidateProfileLength(lat1, lon1, lat2, lon2, d*1.005, 0.01))
+
+		assert.NotNil(t, ValidateProfileLength(lat1, lon1, lat2, lon2, d*1.05, 0.01))
+		assert.NotNil(t, ValidateProfileLength(lat1, lon1, lat2, lon2, d/2, 0.01))
+		assert.NotNil(t, ValidateProfileLength(lat1, lon1, lat2, lon2, 14*Km, 0.01))
+	})
+
+	t.Run("Can calculate wavelengths in different media", func(t *testing.T) {
+		wavelength := FrequencyToWavelengthInMedium(1*GHz, Vacuum)
+		assert.InDelta(t, 0.299792, float64(wavelength), 1e-6)
+
+		wavelength = FrequencyToWavelengthInMedium(1*GHz, Air)
+		assert.InDelta(t, float64(FrequencyToWavelength(1*GHz)), float64(wavelength), 1e-9)
+
+		cable := Medium{VelocityFactor: 0.66}
+		wavelength = FrequencyToWavelengthInMedium(1*GHz, cable)
+		assert.InDelta(t, 0.197863, float64(wavelength), 1e-6)
+
+		freq := WavelengthToFrequencyInMedium(wavelength, cable)
+		assert.InDelta(t, float64(1*GHz), float64(freq), 1e-3)
+	})
+
+	t.Run("Rejects non-positive frequencies when calculating wavelength", func(t *testing.T) {
+		wavelength, err := FrequencyToWavelengthChecked(433 * MHz)
+		assert.Nil(t, err)
+		assert.InDelta(t, 0.6924, float64(wavelength), allowedError)
+
+		_, err = FrequencyToWavelengthChecked(0)
+		assert.NotNil(t, err)
+
+		_, err = FrequencyToWavelengthChecked(-433 * MHz)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Fresnel calculations reject non-positive frequencies", func(t *testing.T) {
+		_, err := FresnelPoint(5*Km, 5*Km, 0, 1)
+		assert.NotNil(t, err)
+
+		_, err = FresnelFirstZoneMax(-2.4*GHz, 10*Km)
+		assert.NotNil(t, err)
+
+		_, err = CalculateFresnelKirckoffDiffractionParam(0, 8*Km, 12*Km, 1*M)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Can calculate the bearing between two lat/lon locations", func(t *testing.T) {
+		assert.InDelta(t, 0.0, CalculateBearing(0, 0, 1, 0), allowedError)
+		assert.InDelta(t, 90.0, CalculateBearing(0, 0, 0, 1), allowedError)
+		assert.InDelta(t, 180.0, CalculateBearing(0, 0, -1, 0), allowedError)
+		assert.InDelta(t, 270.0, CalculateBearing(0, 0, 0, -1), allowedError)
+
+		// Auckland to Wellington
+		assert.InDelta(t, 179.87, CalculateBearing(-36.8485, 174.7633, -41.2865, 174.7762), 0.01)
+	})
+
 	t.Run("Can calculate fresnel points", func(t *testing.T) {
 
 		// Magic Numbers from: http://www.wirelessconnections.net/calcs/FresnelZone.asp
@@ -83,6 +236,61 @@ func TestRFUtils(t *testing.T) {
 		assert.InDelta(t, 55.883, zone, allowedError)
 	})
 
+	t.Run("Can calculate fresnel zones with a custom tolerance", func(t *testing.T) {
+		_, err := FresnelPoint(5*M, 5*M, 433*MHz, 1)
+		assert.NotNil(t, err)
+
+		zone, err := FresnelPointWithTolerance(5*M, 5*M, 433*MHz, 1, 0.5)
+		assert.Nil(t, err)
+		assert.InDelta(t, 1.3157, zone, allowedError)
+
+		_, err = FresnelFirstZoneMax(433*MHz, 5*M)
+		assert.NotNil(t, err)
+
+		zone, err = FresnelFirstZoneMaxWithTolerance(433*MHz, 5*M, 0.5)
+		assert.Nil(t, err)
+		assert.InDelta(t, 0.9304, zone, allowedError)
+	})
+
+	t.Run("Fresnel ellipsoid radius peaks at the midpoint", func(t *testing.T) {
+		max, err := FresnelFirstZoneMax(2.4*GHz, 1*Km)
+		assert.Nil(t, err)
+
+		mid, err := FresnelEllipsoidRadiusAt(0.5, 1*Km, 2.4*GHz, 1)
+		assert.Nil(t, err)
+		assert.InDelta(t, max, mid, allowedError)
+
+		for _, fraction := range []float64{0.1, 0.25, 0.4, 0.6, 0.75, 0.9} {
+			r, err := FresnelEllipsoidRadiusAt(fraction, 1*Km, 2.4*GHz, 1)
+			assert.Nil(t, err)
+			assert.True(t, r < mid, "fraction %.2f", fraction)
+
+			// Symmetric about the midpoint
+			mirror, _ := FresnelEllipsoidRadiusAt(1-fraction, 1*Km, 2.4*GHz, 1)
+			assert.InDelta(t, r, mirror, allowedError)
+		}
+
+		// Higher zones scale with √n
+		second, _ := FresnelEllipsoidRadiusAt(0.5, 1*Km, 2.4*GHz, 2)
+		assert.InDelta(t, mid*math.Sqrt2, second, allowedError)
+
+		end, err := FresnelEllipsoidRadiusAt(0, 1*Km, 2.4*GHz, 1)
+		assert.Nil(t, err)
+		assert.InDelta(t, 0.0, end, allowedError)
+
+		_, err = FresnelEllipsoidRadiusAt(1.5, 1*Km, 2.4*GHz, 1)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Can calculate fresnel ellipsoid volumes", func(t *testing.T) {
+		// Long paths approach a spheroid with semi-axes d/2 and the maximum radius
+		max, _ := FresnelFirstZoneMax(2.4*GHz, 1*Km)
+		expected := 4.0 / 3 * math.Pi * 500 * max * max
+		assert.InDelta(t, expected, FresnelEllipsoidVolume(1*Km, 2.4*GHz, 1), expected*1e-3)
+
+		assert.True(t, FresnelEllipsoidVolume(1*Km, 2.4*GHz, 2) > FresnelEllipsoidVolume(1*Km, 2.4*GHz, 1))
+	})
+
 	t.Run("Can calculate Fresnel-Kirchoff diffraction parameter", func(t *testing.T) {
 		f, d1, d2, h := 900*MHz, 8*Km, 12*Km, -0.334*M
 
@@ -99,6 +307,164 @@ func TestRFUtils(t *testing.T) {
 		assert.InDelta(t, 5.93, float64(loss), allowedError)
 	})
 
+	t.Run("Can calculate rounded obstacle diffraction loss", func(t *testing.T) {
+		loss, err := RoundedObstacleLoss(900*MHz, 8*Km, 12*Km, 20*M, 5*Km)
+		assert.Nil(t, err)
+		assert.InDelta(t, 13.94, float64(loss), 0.01)
+	})
+
+	t.Run("Rounded obstacle loss applies the large mn correction", func(t *testing.T) {
+		// m = 0.0503 and n = 94.87 so mn = 4.77, T = -6 - 20log(mn) + 7.2m^0.5 - (2-17n)m + 3.6m^1.5 - 0.8m²
+		v, _ := CalculateFresnelKirckoffDiffractionParam(2.4*GHz, 10*Km, 10*Km, 300*M)
+		knifeEdge, _ := CalculateFresnelKirchoffLossApprox(v)
+
+		loss, err := RoundedObstacleLoss(2.4*GHz, 10*Km, 10*Km, 300*M, 20*Km)
+		assert.Nil(t, err)
+		assert.InDelta(t, 63.10, float64(loss-knifeEdge), 0.01)
+	})
+
+	t.Run("Rounded obstacles have more loss than knife edges", func(t *testing.T) {
+		for _, h := range []Distance{0, 10, 50} {
+			v, _ := CalculateFresnelKirckoffDiffractionParam(900*MHz, 8*Km, 12*Km, h)
+			knifeEdge, _ := CalculateFresnelKirchoffLossApprox(v)
+
+			previous := knifeEdge
+			for _, radius := range []Distance{100 * M, 1 * Km, 10 * Km} {
+				loss, err := RoundedObstacleLoss(900*MHz, 8*Km, 12*Km, h, radius)
+				assert.Nil(t, err)
+				assert.True(t, loss > previous, "height %.0fm radius %.0fm", h, radius)
+				previous = loss
+			}
+		}
+
+		// Paths clearing the obstacle are never better than the knife edge
+		v, _ := CalculateFresnelKirckoffDiffractionParam(900*MHz, 8*Km, 12*Km, -5)
+		knifeEdge, _ := CalculateFresnelKirchoffLossApprox(v)
+		loss, err := RoundedObstacleLoss(900*MHz, 8*Km, 12*Km, -5, 100*M)
+		assert.Nil(t, err)
+		assert.True(t, loss >= knifeEdge)
+	})
+
+	t.Run("Rounded obstacle loss rejects invalid inputs", func(t *testing.T) {
+		_, err := RoundedObstacleLoss(900*MHz, 8*Km, 12*Km, 20*M, 0)
+		assert.NotNil(t, err)
+		_, err = RoundedObstacleLoss(900*MHz, 8*Km, 12*Km, -100*M, 5*Km)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Can extrapolate foliage loss beyond the Weissberger range", func(t *testing.T) {
+		loss, extrapolated, err := CalculateFoliageLossExtrapolated(2.4*GHz, 500*M)
+		assert.Nil(t, err)
+		assert.True(t, extrapolated)
+		assert.InDelta(t, 1.33*math.Pow(2.4, 0.284)*math.Pow(500, 0.588), float64(loss), allowedError)
+
+		// Extrapolation continues the validated curve
+		edge, _ := CalculateFoliageLoss(2.4*GHz, WeissbergerMaxDist)
+		assert.True(t, loss > edge)
+		beyond, _, _ := CalculateFoliageLossExtrapolated(2.4*GHz, WeissbergerMaxDist+0.001)
+		assert.InDelta(t, float64(edge), float64(beyond), allowedError)
+	})
+
+	t.Run("Foliage loss within the Weissberger range is not extrapolated", func(t *testing.T) {
+		for _, depth := range []Distance{10, 100, 400} {
+			expected, _ := CalculateFoliageLoss(2.4*GHz, depth)
+
+			loss, extrapolated, err := CalculateFoliageLossExtrapolated(2.4*GHz, depth)
+			assert.Nil(t, err)
+			assert.False(t, extrapolated)
+			assert.Equal(t, expected, loss)
+		}
+
+		_, _, err := CalculateFoliageLossExtrapolated(100*MHz, 500*M)
+		assert.NotNil(t, err)
+
+		// Frequency limits are reported consistently within and beyond the validated depths
+		_, expected := CalculateFoliageLoss(100*MHz, 100*M)
+		assert.EqualError(t, err, expected.Error())
+	})
+
+	t.Run("P.833 foliage loss asymptotes to the maximum loss", func(t *testing.T) {
+		previous := Attenuation(0)
+		for _, depth := range []Distance{1, 10, 100, 1000} {
+			loss := CalculateFoliageLossP833(2.4*GHz, depth, 30)
+			assert.True(t, loss > previous, "depth %.0fm", depth)
+			assert.True(t, loss < 30)
+			previous = loss
+		}
+
+		assert.InDelta(t, 30.0, float64(CalculateFoliageLossP833(2.4*GHz, 10*Km, 30)), 1e-6)
+		assert.Equal(t, Attenuation(0), CalculateFoliageLossP833(2.4*GHz, 0, 30))
+	})
+
+	t.Run("P.833 foliage loss matches Weissberger for shallow vegetation", func(t *testing.T) {
+		weissberger, _ := CalculateFoliageLoss(2.4*GHz, 1*M)
+		assert.InDelta(t, float64(weissberger), float64(CalculateFoliageLossP833(2.4*GHz, 1*M, 100)), 0.01)
+
+		// Unlike Weissberger, deep vegetation saturates
+		deep, _, _ := CalculateFoliageLossExtrapolated(2.4*GHz, 2*Km)
+		assert.True(t, CalculateFoliageLossP833(2.4*GHz, 2*Km, 30) < deep)
+	})
+
+	t.Run("Single screen diffraction depends on the screen height relative to the line of sight", func(t *testing.T) {
+		// Line of sight passes 5m above ground at the screen
+		grazing, err := SingleScreenDiffraction(900*MHz, 200*M, 50*M, 5, 25, 0)
+		assert.Nil(t, err)
+		assert.InDelta(t, 6.0, float64(grazing), 0.1)
+
+		above, err := SingleScreenDiffraction(900*MHz, 200*M, 50*M, 10, 25, 0)
+		assert.Nil(t, err)
+		assert.True(t, above > grazing)
+
+		below, err := SingleScreenDiffraction(900*MHz, 200*M, 50*M, 4, 25, 0)
+		assert.Nil(t, err)
+		assert.True(t, below < grazing && below > 0)
+
+		clear, err := SingleScreenDiffraction(900*MHz, 200*M, 50*M, 0, 25, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, Attenuation(0), clear)
+	})
+
+	t.Run("Single screen diffraction matches the knife edge approximation", func(t *testing.T) {
+		v, _ := CalculateFresnelKirckoffDiffractionParam(900*MHz, 200*M, 50*M, 5)
+		expected, _ := CalculateFresnelKirchoffLossApprox(v)
+
+		loss, err := SingleScreenDiffraction(900*MHz, 200*M, 50*M, 10, 25, 0)
+		assert.Nil(t, err)
+		assert.InDelta(t, float64(expected), float64(loss), allowedError)
+
+		_, err = SingleScreenDiffraction(900*MHz, 0, 50*M, 25, 25, 0)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Can calculate path loss through foliage", func(t *testing.T) {
+		for _, depth := range []Distance{10, 100, 400} {
+			foliage, err := CalculateFoliageLoss(2.4*GHz, depth)
+			assert.Nil(t, err)
+
+			loss, err := CalculatePathWithFoliage(2.4*GHz, 1*Km, depth)
+			assert.Nil(t, err)
+			assert.InDelta(t, float64(CalculateFreeSpacePathLoss(2.4*GHz, 1*Km)+foliage), float64(loss), allowedError, "depth %.0fm", depth)
+		}
+
+		loss, err := CalculatePathWithFoliage(2.4*GHz, 1*Km, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, CalculateFreeSpacePathLoss(2.4*GHz, 1*Km), loss)
+	})
+
+	t.Run("Path loss through foliage wraps validity errors", func(t *testing.T) {
+		_, foliageErr := CalculateFoliageLoss(2.4*GHz, 500*M)
+
+		_, err := CalculatePathWithFoliage(2.4*GHz, 1*Km, 500*M)
+		assert.NotNil(t, err)
+		assert.Equal(t, foliageErr.Error(), errors.Unwrap(err).Error())
+
+		_, err = CalculatePathWithFoliage(100*MHz, 1*Km, 100*M)
+		assert.NotNil(t, err)
+
+		_, err = CalculatePathWithFoliage(2.4*GHz, 100*M, 200*M)
+		assert.NotNil(t, err)
+	})
+
 	t.Run("Normalises terrain paths against slope", func(t *testing.T) {
 		tests := []struct {
 			name         string
@@ -277,6 +643,55 @@ func TestRFUtils(t *testing.T) {
 		}
 	})
 
+	t.Run("Spaced terrain paths match evenly spaced paths", func(t *testing.T) {
+		terrain := []float64{0.0, 5.0, 12.0, 7.0, 3.0, 0.0}
+		distances := []float64{0, 100, 200, 300, 400, 500}
+
+		x1, y1, d1 := TerrainToPathXY(10.0, 25.0, 500, terrain)
+		x2, y2, d2, err := TerrainToPathXYSpaced(10.0, 25.0, distances, terrain)
+		assert.Nil(t, err)
+
+		assert.InDelta(t, d1, d2, allowedError)
+		for i := range terrain {
+			assert.InDelta(t, x1[i], x2[i], allowedError)
+			assert.InDelta(t, y1[i], y2[i], allowedError)
+		}
+	})
+
+	t.Run("Spaced terrain paths account for uneven sampling", func(t *testing.T) {
+		// The same peak sampled at a different point along the path
+		x, y, _, err := TerrainToPathXYSpaced(10.0, 10.0, []float64{0, 50, 100, 500}, []float64{0, 0, 20, 0})
+		assert.Nil(t, err)
+		assert.InDelta(t, 100.0, x[2], allowedError)
+		assert.InDelta(t, 10.0, y[2], allowedError)
+
+		x, y, _ = TerrainToPathXY(10.0, 10.0, 500, []float64{0, 0, 20, 0})
+		assert.InDelta(t, 333.33, x[2], 0.01)
+		assert.InDelta(t, 10.0, y[2], allowedError)
+	})
+
+	t.Run("Spaced terrain paths reject invalid profiles", func(t *testing.T) {
+		_, _, _, err := TerrainToPathXYSpaced(10.0, 10.0, []float64{0, 50}, []float64{0, 0, 20})
+		assert.NotNil(t, err)
+
+		_, _, _, err = TerrainToPathXYSpaced(10.0, 10.0, []float64{}, []float64{})
+		assert.NotNil(t, err)
+
+		_, _, _, err = TerrainToPathXYSpaced(10.0, 10.0, []float64{0, 0}, []float64{0, 0})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Terrain paths handle empty terrain", func(t *testing.T) {
+		x, y, d := TerrainToPathXY(1, 2, 100, []float64{})
+		assert.Empty(t, x)
+		assert.Empty(t, y)
+		assert.InDelta(t, math.Sqrt(100*100+1), d, allowedError)
+
+		x, y, _ = TerrainToPathXY(1, 2, 100, []float64{5})
+		assert.Empty(t, x)
+		assert.Empty(t, y)
+	})
+
 	t.Run("Computes maximum fresnel zone impingement over terrain", func(t *testing.T) {
 		tests := []struct {
 			name   string
@@ -316,4 +731,68 @@ func TestRFUtils(t *testing.T) {
 		}
 	})
 
+	t.Run("Reports points skipped when computing fresnel zone impingement", func(t *testing.T) {
+		// Short path where all but the central points are too close to the endpoints
+		terrain := []float64{-100.0, -100.0, -100.0, -100.0, -100.0, 0.0, -100.0, -100.0, -100.0, -100.0, -100.0}
+		x, y, d := TerrainToPathXY(0.0, 0.0, 20.0*M, terrain)
+
+		i, p, skipped, err := FresnelImpingementMaxChecked(x, y, Distance(d), 433*MHz)
+		assert.NotNil(t, err)
+		assert.Equal(t, 6, skipped)
+		assert.InDelta(t, 0.5, i, allowedError)
+		assert.InDelta(t, 10.0, float64(p), allowedError)
+
+		x, y, d = TerrainToPathXY(0.0, 0.0, 50.0*M, []float64{-100.0, -100.0, 0.0, -100.0, -100.0})
+
+		_, _, skipped, err = FresnelImpingementMaxChecked(x, y, Distance(d), 433*MHz)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, skipped)
+	})
+
+	t.Run("Computes fresnel zone impingement over short paths with a custom tolerance", func(t *testing.T) {
+		x, y, d := TerrainToPathXY(0.0, 0.0, 10.0*M, []float64{-100.0, -100.0, 0.0, -100.0, -100.0})
+
+		// Default tolerance skips all points on a path this short
+		i, _ := FresnelImpingementMax(x, y, Distance(d), 433*MHz)
+		assert.InDelta(t, 0.0, i, allowedError)
+
+		i, p := FresnelImpingementMaxWithTolerance(x, y, Distance(d), 433*MHz, 0.5)
+		assert.InDelta(t, 0.5, i, allowedError)
+		assert.InDelta(t, 5.0, float64(p), allowedError)
+	})
+
+	t.Run("Can export terrain path analysis to CSV", func(t *testing.T) {
+		x, y, d := TerrainToPathXY(0.0, 0.0, 50.0*M, []float64{-100.0, -100.0, 0.0, -100.0, -100.0})
+
+		radii := make([]float64, len(x))
+		for i := 1; i < len(x)-1; i++ {
+			radii[i], _ = FresnelPoint(Distance(x[i]), Distance(d-x[i]), 433*MHz, 1)
+		}
+
+		buffer := bytes.NewBuffer([]byte{})
+		err := ExportPathCSV(buffer, x, y, radii)
+		assert.Nil(t, err)
+
+		records, err := csv.NewReader(buffer).ReadAll()
+		assert.Nil(t, err)
+		assert.Len(t, records, len(x)+1)
+		assert.Equal(t, []string{"distance_m", "clearance_m", "fresnel_radius_m"}, records[0])
+
+		for i, record := range records[1:] {
+			dist, _ := strconv.ParseFloat(record[0], 64)
+			clearance, _ := strconv.ParseFloat(record[1], 64)
+			radius, _ := strconv.ParseFloat(record[2], 64)
+
+			assert.InDelta(t, x[i], dist, allowedError, "index %d", i)
+			assert.InDelta(t, -y[i], clearance, allowedError, "index %d", i)
+			assert.InDelta(t, radii[i], radius, allowedError, "index %d", i)
+		}
+	})
+
+	t.Run("CSV export rejects mismatched path data", func(t *testing.T) {
+		buffer := bytes.NewBuffer([]byte{})
+		err := ExportPathCSV(buffer, []float64{0, 1}, []float64{0, 1}, []float64{0})
+		assert.NotNil(t, err)
+	})
+
 }
@@ -0,0 +1,386 @@
+package rf
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Terrain path planning helpers
+
+const (
+	// MastHeightStep is the resolution in meters used when searching for mast heights
+	MastHeightStep = 0.1
+	// MastHeightTolerance is the default precision in meters to which bisection mast height searches are resolved
+	MastHeightTolerance = 0.01
+	// MastHeightMax is the largest additional mast height in meters considered when searching for mast heights
+	MastHeightMax = 500.0
+)
+
+// ObstructionClass describes the dominant obstruction of a terrain path
+type ObstructionClass string
+
+// Terrain path obstruction classes
+const (
+	// ObstructionClear paths have acceptable first fresnel zone clearance (see FresnelObstructionOK)
+	ObstructionClear ObstructionClass = "clear"
+	// ObstructionGrazing paths have line of sight but insufficient first fresnel zone clearance
+	ObstructionGrazing ObstructionClass = "grazing"
+	// ObstructionKnifeEdge paths have line of sight blocked within the first fresnel zone, suitable for knife edge diffraction
+	ObstructionKnifeEdge ObstructionClass = "knife-edge"
+	// ObstructionBlocked paths have the entire first fresnel zone blocked
+	ObstructionBlocked ObstructionClass = "obstructed"
+)
+
+// ClassifyObstruction classifies the dominant obstruction of a terrain path between two points of set heights,
+// returning the location of the worst fresnel zone impingement for clear and grazing paths, or of the Bullington
+// equivalent knife edge where line of sight is blocked. An error is returned where the fresnel zone impingement
+// could not be calculated at every point.
+func ClassifyObstruction(p1, p2 float64, d Distance, f Frequency, terrain []float64) (ObstructionClass, Distance, error) {
+	if len(terrain) < 3 {
+		return "", 0, fmt.Errorf("Terrain profile must contain at least three points (points: %d)", len(terrain))
+	}
+
+	x, y, l := TerrainToPathXY(p1, p2, d, terrain)
+
+	impingement, point, _, err := FresnelImpingementMaxChecked(x, y, Distance(l), f)
+	if err != nil {
+		return "", 0, err
+	}
+
+	// Terrain at the line of sight impinges on half of the fresnel zone
+	switch {
+	case impingement <= FresnelObstructionOK:
+		return ObstructionClear, point, nil
+	case impingement <= 0.5:
+		return ObstructionGrazing, point, nil
+	}
+
+	edge, _, _ := BullingtonFigure12Method(x, y, Distance(l))
+	if impingement < 1 {
+		return ObstructionKnifeEdge, edge, nil
+	}
+	return ObstructionBlocked, edge, nil
+}
+
+// TerrainRoughness calculates the terrain irregularity parameter Δh used by the Longley-Rice (ITM) model, the
+// interdecile (10% to 90%) range of terrain heights about a least squares linear fit to the profile. This removes
+// any overall slope so that only the irregularity of the terrain is measured.
+// https://en.wikipedia.org/wiki/Longley%E2%80%93Rice_model
+func TerrainRoughness(terrain []float64) float64 {
+	n := len(terrain)
+	if n < 3 {
+		return 0
+	}
+
+	// Least squares fit of height against sample index
+	meanX, meanY := float64(n-1)/2, 0.0
+	for _, h := range terrain {
+		meanY += h / float64(n)
+	}
+	sxy, sxx := 0.0, 0.0
+	for i, h := range terrain {
+		sxy += (float64(i) - meanX) * (h - meanY)
+		sxx += (float64(i) - meanX) * (float64(i) - meanX)
+	}
+	slope := sxy / sxx
+
+	deviations := make([]float64, n)
+	for i, h := range terrain {
+		deviations[i] = h - (meanY + slope*(float64(i)-meanX))
+	}
+	sort.Float64s(deviations)
+
+	return percentile(deviations, 0.9) - percentile(deviations, 0.1)
+}
+
+// percentile calculates the percentile (0 to 1) of sorted values using linear interpolation between samples
+func percentile(sorted []float64, p float64) float64 {
+	position := p * float64(len(sorted)-1)
+	i := int(math.Floor(position))
+	if i >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+
+	frac := position - float64(i)
+	return sorted[i] + frac*(sorted[i+1]-sorted[i])
+}
+
+// MeetsFresnelClearance checks whether the clearance of terrain below the line of sight between two points of set
+// heights, as a fraction of the first fresnel zone radius, meets requiredFraction (ie. 0.6 for the 60% rule) at every
+// point along the path, returning the margin of the worst case clearance over the required fraction (negative where
+// the requirement is not met)
+func MeetsFresnelClearance(p1, p2 float64, d Distance, f Frequency, terrain []float64, requiredFraction float64) (bool, float64, error) {
+	if requiredFraction <= 0 || requiredFraction > 1 {
+		return false, 0, fmt.Errorf("Required clearance fraction must be between 0 and 1 (fraction: %.2f)", requiredFraction)
+	}
+	if len(terrain) < 3 {
+		return false, 0, fmt.Errorf("Terrain profile must contain at least three points (points: %d)", len(terrain))
+	}
+
+	clearance, err := pathClearance(p1, p2, d, f, terrain)
+	if err != nil {
+		return false, 0, err
+	}
+
+	margin := clearance - requiredFraction
+
+	return margin >= 0, margin, nil
+}
+
+// ObstructionDetail describes the point of worst first fresnel zone impingement along a terrain path
+type ObstructionDetail struct {
+	// Point is the distance along the path of the worst impingement
+	Point Distance
+	// Impingement is the proportion of the first fresnel zone obstructed at the point
+	Impingement float64
+	// Elevation is the terrain elevation at the point, or NaN where no terrain impinges on the fresnel zone
+	Elevation float64
+	// ClearanceRequired is the additional clearance (in meters, approximately vertical for shallow paths) required
+	// along the whole path, ie. by raising both ends, for terrain to clear ObstructionClearanceFraction of the first
+	// fresnel zone radius at every point
+	ClearanceRequired float64
+}
+
+// ObstructionClearanceFraction is the fraction of the first fresnel zone radius cleared by the ClearanceRequired of
+// an ObstructionDetail, matching the 60% rule as evaluated by MeetsFresnelClearance
+const ObstructionClearanceFraction = 0.6
+
+// obstructionClearanceRounding is added to non-zero required clearances (in meters) so that paths raised by them are
+// not judged marginally unclear due to floating point error
+const obstructionClearanceRounding = 1e-9
+
+// WorstObstructionDetail finds the point of worst first fresnel zone impingement along a terrain path between two
+// points of set heights as FresnelImpingementMax, additionally reporting the terrain elevation at that point and the
+// additional clearance required for ObstructionClearanceFraction clearance, returning an error where any points were
+// skipped as the fresnel calculation is invalid (wavelength is not << d1 or d2)
+func WorstObstructionDetail(p1, p2 float64, d Distance, f Frequency, terrain []float64) (ObstructionDetail, error) {
+	if len(terrain) < 3 {
+		return ObstructionDetail{}, fmt.Errorf("Terrain profile must contain at least three points (points: %d)", len(terrain))
+	}
+
+	x, y, l := TerrainToPathXY(p1, p2, d, terrain)
+
+	deficit, skipped := fresnelClearanceDeficit(x, y, Distance(l), f, ObstructionClearanceFraction)
+	if skipped > 0 {
+		return ObstructionDetail{}, fmt.Errorf("Fresnel clearance skipped %d of %d points where distance is not >> wavelength", skipped, len(x)-2)
+	}
+
+	impingement, point, index, _ := fresnelImpingementMax(x, y, Distance(l), f, FresnelMinDistanceWavelengthRadio)
+
+	detail := ObstructionDetail{Point: point, Impingement: impingement, Elevation: math.NaN()}
+	if deficit > 0 {
+		detail.ClearanceRequired = deficit + obstructionClearanceRounding
+	}
+	if index >= 0 {
+		detail.Elevation = terrain[index]
+	}
+
+	return detail, nil
+}
+
+// Site describes a path endpoint, separating the ground elevation from the antenna height to avoid mixing datums
+// when combining antenna heights with terrain elevations
+type Site struct {
+	// GroundElevation is the ground elevation above mean sea level (AMSL) in meters
+	GroundElevation float64
+	// AntennaHeight is the antenna height above ground level (AGL) in meters
+	AntennaHeight float64
+}
+
+// SiteOnTerrain creates a Site at a point of a terrain profile with the provided antenna height above ground, where
+// negative indices count back from the end of the profile (ie. -1 for the last point), returning an error where the
+// index is outside of the profile
+func SiteOnTerrain(terrain []float64, index int, antennaHeight float64) (Site, error) {
+	i := index
+	if i < 0 {
+		i = len(terrain) + i
+	}
+	if i < 0 || i >= len(terrain) {
+		return Site{}, fmt.Errorf("Terrain index %d is outside of the profile (points: %d)", index, len(terrain))
+	}
+	return Site{GroundElevation: terrain[i], AntennaHeight: antennaHeight}, nil
+}
+
+// AbsoluteHeight returns the antenna height above mean sea level, in the same datum as terrain elevations
+// as expected by the p1 and p2 endpoint heights of TerrainToPathXY
+func (s Site) AbsoluteHeight() float64 {
+	return s.GroundElevation + s.AntennaHeight
+}
+
+// SiteTerrainToPathXY converts terrain between two sites into distances from the path between their antennas,
+// as TerrainToPathXY using the absolute antenna heights of each site
+func SiteTerrainToPathXY(a, b Site, d Distance, terrain []float64) (x, y []float64, d2 float64) {
+	return TerrainToPathXY(a.AbsoluteHeight(), b.AbsoluteHeight(), d, terrain)
+}
+
+// mastHeightTieTolerance is the clearance difference below which raising either mast is considered equivalent
+const mastHeightTieTolerance = 1e-6
+
+// pathImpingement calculates the maximum first fresnel zone impingement for a terrain path between two points of set heights
+func pathImpingement(p1, p2 float64, d Distance, f Frequency, terrain []float64) float64 {
+	x, y, l := TerrainToPathXY(p1, p2, d, terrain)
+	impingement, _ := FresnelImpingementMax(x, y, Distance(l), f)
+	return impingement
+}
+
+// pathClearance calculates the minimum first fresnel zone clearance (as a fraction of the zone radius) for a terrain
+// path between two points of set heights, returning an error where any points were skipped as the fresnel
+// calculation is invalid (wavelength is not << d1 or d2)
+func pathClearance(p1, p2 float64, d Distance, f Frequency, terrain []float64) (float64, error) {
+	x, y, l := TerrainToPathXY(p1, p2, d, terrain)
+	clearance, _, skipped := fresnelClearanceMin(x, y, Distance(l), f)
+	if skipped > 0 {
+		return clearance, fmt.Errorf("Fresnel clearance skipped %d of %d points where distance is not >> wavelength", skipped, len(x)-2)
+	}
+	return clearance, nil
+}
+
+// OptimalAntennaHeights finds the minimal additional mast heights at each end of a terrain path required for the
+// clearance of terrain below the line of sight, as a fraction of the first fresnel zone radius, to meet
+// targetClearance (ie. 0.6 for 60% clearance) as MeetsFresnelClearance. Endpoints are raised iteratively in
+// MastHeightStep increments, choosing at each step whichever end best improves the clearance, until the target
+// is met or MastHeightMax is exceeded.
+func OptimalAntennaHeights(p1Base, p2Base float64, d Distance, f Frequency, terrain []float64, targetClearance float64) (p1, p2 float64, err error) {
+	if targetClearance <= 0 || targetClearance > 1 {
+		return 0, 0, fmt.Errorf("Target clearance must be between 0 and 1 (clearance: %.2f)", targetClearance)
+	}
+	if len(terrain) < 3 {
+		return 0, 0, fmt.Errorf("Terrain profile must contain at least three points (points: %d)", len(terrain))
+	}
+
+	clearance, err := pathClearance(p1Base, p2Base, d, f, terrain)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for clearance < targetClearance {
+		if p1 > MastHeightMax || p2 > MastHeightMax {
+			return 0, 0, fmt.Errorf("Unable to achieve %.0f%% clearance with masts below %.0fm", targetClearance*100, MastHeightMax)
+		}
+
+		c1, err := pathClearance(p1Base+p1+MastHeightStep, p2Base+p2, d, f, terrain)
+		if err != nil {
+			return 0, 0, err
+		}
+		c2, err := pathClearance(p1Base+p1, p2Base+p2+MastHeightStep, d, f, terrain)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		// Raise whichever end improves clearance most, balancing mast heights where (approximately) equal
+		if c1 > c2+mastHeightTieTolerance || (c1 >= c2-mastHeightTieTolerance && p1 <= p2) {
+			p1, clearance = p1+MastHeightStep, c1
+		} else {
+			p2, clearance = p2+MastHeightStep, c2
+		}
+	}
+
+	return p1, p2, nil
+}
+
+// RequiredMastHeight finds the mast height above the terrain at the far end of a path required for the clearance
+// of terrain below the line of sight, as a fraction of the first fresnel zone radius, to meet clearancePercent
+// (ie. 60 for 60% clearance) as MeetsFresnelClearance, with the near end fixed at an absolute height of fixedEnd.
+// The height is found by bisection to within MastHeightTolerance, returning an error where the path cannot be
+// cleared with a mast below MastHeightMax.
+func RequiredMastHeight(fixedEnd float64, d Distance, f Frequency, terrain []float64, clearancePercent float64) (float64, error) {
+	opts := DefaultSolverOptions
+	opts.Tolerance = MastHeightTolerance
+	return RequiredMastHeightWithOptions(fixedEnd, d, f, terrain, clearancePercent, opts)
+}
+
+// RequiredMastHeightWithOptions finds the required mast height as RequiredMastHeight, resolving the height to within
+// the tolerance (in meters) of the provided solver options
+func RequiredMastHeightWithOptions(fixedEnd float64, d Distance, f Frequency, terrain []float64, clearancePercent float64, opts SolverOptions) (float64, error) {
+	if clearancePercent <= 0 || clearancePercent > 100 {
+		return 0, fmt.Errorf("Clearance percentage must be between 0 and 100 (clearance: %.2f)", clearancePercent)
+	}
+	if len(terrain) < 2 {
+		return 0, fmt.Errorf("Terrain profile must contain at least two points (points: %d)", len(terrain))
+	}
+
+	required := clearancePercent / 100
+	base := terrain[len(terrain)-1]
+
+	// Record the first clearance error, as skipped points otherwise appear infinitely clear
+	var clearanceErr error
+	excess := func(h float64) float64 {
+		clearance, err := pathClearance(fixedEnd, base+h, d, f, terrain)
+		if err != nil && clearanceErr == nil {
+			clearanceErr = err
+		}
+		return clearance - required
+	}
+
+	low, high := excess(0), excess(MastHeightMax)
+	if clearanceErr != nil {
+		return 0, clearanceErr
+	}
+	if low >= 0 {
+		return 0, nil
+	}
+	if high < 0 {
+		return 0, fmt.Errorf("Unable to achieve %.0f%% clearance with a mast below %.0fm", clearancePercent, MastHeightMax)
+	}
+
+	// Clearance increases monotonically with mast height, so bisect between unclear and clear heights
+	h, err := Bisect(excess, 0, MastHeightMax, opts)
+	if err != nil {
+		return 0, fmt.Errorf("Mast height search failed: %w", err)
+	}
+	if clearanceErr != nil {
+		return 0, clearanceErr
+	}
+
+	// Round up to the clear end of the final interval
+	return h + opts.Tolerance/2, nil
+}
+
+// ClutterType is a land cover type adding obstructions above bare-earth terrain
+type ClutterType string
+
+// Supported clutter types
+const (
+	ClutterOpen     ClutterType = "open"
+	ClutterWater    ClutterType = "water"
+	ClutterForest   ClutterType = "forest"
+	ClutterSuburban ClutterType = "suburban"
+	ClutterUrban    ClutterType = "urban"
+)
+
+// ClutterHeights are the typical representative heights in meters of each clutter type
+var ClutterHeights = map[ClutterType]float64{
+	ClutterOpen:     0,
+	ClutterWater:    0,
+	ClutterForest:   10,
+	ClutterSuburban: 9,
+	ClutterUrban:    20,
+}
+
+// AddClutterHeights adds clutter (tree or building) heights to a bare-earth terrain profile, returning an effective
+// profile for use with FresnelImpingementMax and the Bullington method. Samples without a corresponding clutter
+// height are unchanged.
+func AddClutterHeights(terrain []float64, clutterHeights []float64) []float64 {
+	effective := make([]float64, len(terrain))
+	copy(effective, terrain)
+
+	for i := range effective {
+		if i < len(clutterHeights) {
+			effective[i] += clutterHeights[i]
+		}
+	}
+
+	return effective
+}
+
+// AddClutterTypes adds the representative ClutterHeights for each sample's clutter type to a bare-earth terrain
+// profile as AddClutterHeights. Unknown clutter types add no height.
+func AddClutterTypes(terrain []float64, clutter []ClutterType) []float64 {
+	heights := make([]float64, len(clutter))
+	for i, c := range clutter {
+		heights[i] = ClutterHeights[c]
+	}
+
+	return AddClutterHeights(terrain, heights)
+}
@@ -0,0 +1,392 @@
+package rf
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Flat terrain with a 5m obstruction at the midpoint
+var obstructedTerrain = []float64{0.0, 0.0, 0.0, 0.0, 0.0, 5.0, 0.0, 0.0, 0.0, 0.0, 0.0}
+
+// Flat terrain with a 5m obstruction a quarter of the way along the path
+var skewedTerrain = []float64{0.0, 0.0, 5.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0}
+
+// clearanceOf calculates the path clearance, ignoring errors for paths known to be valid
+func clearanceOf(p1, p2 float64, d Distance, f Frequency, terrain []float64) float64 {
+	clearance, _ := pathClearance(p1, p2, d, f, terrain)
+	return clearance
+}
+
+func TestTerrain(t *testing.T) {
+
+	t.Run("Optimal antenna heights are zero for clear paths", func(t *testing.T) {
+		p1, p2, err := OptimalAntennaHeights(20.0, 20.0, 500*M, 433*MHz, obstructedTerrain, 0.6)
+		assert.Nil(t, err)
+		assert.InDelta(t, 0.0, p1, allowedError)
+		assert.InDelta(t, 0.0, p2, allowedError)
+	})
+
+	t.Run("Optimal antenna heights clear an obstructed path", func(t *testing.T) {
+		p1, p2, err := OptimalAntennaHeights(2.0, 2.0, 500*M, 433*MHz, obstructedTerrain, 0.6)
+		assert.Nil(t, err)
+
+		assert.True(t, clearanceOf(2.0+p1, 2.0+p2, 500*M, 433*MHz, obstructedTerrain) >= 0.6)
+		assert.True(t, clearanceOf(2.0+p1-MastHeightStep, 2.0+p2-MastHeightStep, 500*M, 433*MHz, obstructedTerrain) < 0.6)
+
+		// Symmetric obstruction requires balanced masts
+		assert.InDelta(t, p1, p2, MastHeightStep*1.01)
+
+		// The line of sight must pass 0.6 of the 9.30m fresnel zone radius (5.58m) above the 5m obstruction
+		radius, _ := FresnelPoint(250*M, 250*M, 433*MHz, 1)
+		assert.InDelta(t, 5+0.6*radius-2, p1, MastHeightStep)
+		assert.InDelta(t, 8.6, p1, MastHeightStep/2)
+	})
+
+	t.Run("Optimal antenna heights favour the end nearest an obstruction", func(t *testing.T) {
+		p1, p2, err := OptimalAntennaHeights(2.0, 2.0, 500*M, 433*MHz, skewedTerrain, 0.6)
+		assert.Nil(t, err)
+
+		assert.True(t, clearanceOf(2.0+p1, 2.0+p2, 500*M, 433*MHz, skewedTerrain) >= 0.6)
+		assert.True(t, p1 > p2)
+	})
+
+	t.Run("Optimal antenna heights rejects invalid clearance targets", func(t *testing.T) {
+		_, _, err := OptimalAntennaHeights(2.0, 2.0, 500*M, 433*MHz, obstructedTerrain, 0)
+		assert.NotNil(t, err)
+
+		_, _, err = OptimalAntennaHeights(2.0, 2.0, 500*M, 433*MHz, obstructedTerrain, 1.5)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Optimal antenna heights rejects paths too short for fresnel calculations", func(t *testing.T) {
+		_, _, err := OptimalAntennaHeights(0, 0, 1, 433*MHz, []float64{0, 5, 0}, 0.6)
+		assert.NotNil(t, err)
+
+		_, _, err = OptimalAntennaHeights(0, 0, 500*M, 433*MHz, []float64{}, 0.6)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Required mast height is zero for clear paths", func(t *testing.T) {
+		h, err := RequiredMastHeight(40.0, 500*M, 433*MHz, obstructedTerrain, 60)
+		assert.Nil(t, err)
+		assert.InDelta(t, 0.0, h, allowedError)
+	})
+
+	t.Run("Required mast height clears obstructed paths", func(t *testing.T) {
+		for _, terrain := range [][]float64{obstructedTerrain, skewedTerrain} {
+			h, err := RequiredMastHeight(2.0, 500*M, 433*MHz, terrain, 60)
+			assert.Nil(t, err)
+
+			assert.True(t, clearanceOf(2.0, h, 500*M, 433*MHz, terrain) >= 0.6)
+			assert.True(t, clearanceOf(2.0, h-MastHeightTolerance, 500*M, 433*MHz, terrain) < 0.6)
+		}
+	})
+
+	t.Run("Required mast height provides 60% of the fresnel zone radius", func(t *testing.T) {
+		// The line of sight must pass 0.6 of the 9.30m fresnel zone radius (5.58m) above the 5m obstruction at the
+		// midpoint, so 10.58m above ground, requiring the far end at 2·10.58 - 2 = 19.16m (less than 0.01m of slope)
+		radius, _ := FresnelPoint(250*M, 250*M, 433*MHz, 1)
+		expected := 2*(5+0.6*radius) - 2.0
+		assert.InDelta(t, 19.16, expected, 0.01)
+
+		h, err := RequiredMastHeight(2.0, 500*M, 433*MHz, obstructedTerrain, 60)
+		assert.Nil(t, err)
+		assert.InDelta(t, expected, h, 0.02)
+	})
+
+	t.Run("Required mast height uses the provided solver tolerance", func(t *testing.T) {
+		fine, err := RequiredMastHeightWithOptions(2.0, 500*M, 433*MHz, obstructedTerrain, 60, SolverOptions{MaxIterations: 100, Tolerance: 1e-6})
+		assert.Nil(t, err)
+
+		coarse, err := RequiredMastHeightWithOptions(2.0, 500*M, 433*MHz, obstructedTerrain, 60, SolverOptions{MaxIterations: 100, Tolerance: 1})
+		assert.Nil(t, err)
+		assert.True(t, coarse >= fine)
+		assert.InDelta(t, fine, coarse, 1)
+
+		_, err = RequiredMastHeightWithOptions(2.0, 500*M, 433*MHz, obstructedTerrain, 60, SolverOptions{})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Required mast height rejects paths too short for fresnel calculations", func(t *testing.T) {
+		_, err := RequiredMastHeight(0, 1, 433*MHz, []float64{0, 5, 0}, 60)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Required mast height increases with clearance", func(t *testing.T) {
+		h60, err := RequiredMastHeight(2.0, 500*M, 433*MHz, obstructedTerrain, 60)
+		assert.Nil(t, err)
+
+		h100, err := RequiredMastHeight(2.0, 500*M, 433*MHz, obstructedTerrain, 100)
+		assert.Nil(t, err)
+
+		assert.True(t, h100 > h60)
+	})
+
+	t.Run("Required mast height errors for unreachable clearance", func(t *testing.T) {
+		terrain := []float64{0.0, 100.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0}
+
+		_, err := RequiredMastHeight(2.0, 500*M, 433*MHz, terrain, 60)
+		assert.NotNil(t, err)
+
+		_, err = RequiredMastHeight(2.0, 500*M, 433*MHz, obstructedTerrain, 0)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Clutter heights are added to terrain", func(t *testing.T) {
+		effective := AddClutterHeights(obstructedTerrain, []float64{0, 10, 10, 10, 10, 10, 10, 10, 10, 10, 0})
+		assert.Len(t, effective, len(obstructedTerrain))
+		assert.InDelta(t, 15.0, effective[5], allowedError)
+		assert.InDelta(t, 0.0, effective[0], allowedError)
+
+		// The bare-earth profile is unchanged
+		assert.InDelta(t, 5.0, obstructedTerrain[5], allowedError)
+
+		// Missing clutter samples are treated as open ground
+		partial := AddClutterHeights(obstructedTerrain, []float64{1, 1})
+		assert.Equal(t, []float64{1, 1, 0, 0, 0, 5, 0, 0, 0, 0, 0}, partial)
+	})
+
+	t.Run("Forest canopy increases impingement", func(t *testing.T) {
+		clutter := make([]ClutterType, len(obstructedTerrain))
+		for i := range clutter {
+			clutter[i] = ClutterOpen
+		}
+
+		bare := pathImpingement(18.0, 18.0, 500*M, 433*MHz, AddClutterTypes(obstructedTerrain, clutter))
+		assert.InDelta(t, pathImpingement(18.0, 18.0, 500*M, 433*MHz, obstructedTerrain), bare, allowedError)
+
+		// Forest around the obstruction
+		clutter[4], clutter[5], clutter[6] = ClutterForest, ClutterForest, ClutterForest
+		forest := pathImpingement(18.0, 18.0, 500*M, 433*MHz, AddClutterTypes(obstructedTerrain, clutter))
+		assert.True(t, forest > bare)
+
+		// Urban clutter is taller again
+		clutter[4], clutter[5], clutter[6] = ClutterUrban, ClutterUrban, ClutterUrban
+		urban := pathImpingement(18.0, 18.0, 500*M, 433*MHz, AddClutterTypes(obstructedTerrain, clutter))
+		assert.True(t, urban > forest)
+		assert.True(t, urban > FresnelObstructionOK)
+	})
+}
+
+func TestClassifyObstruction(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		terrain []float64
+		class   ObstructionClass
+		point   Distance
+	}{
+		{"Clear", []float64{-100.0, -100.0, -100.0, -100.0, -100.0}, ObstructionClear, 25.0},
+		{"Grazing", []float64{-100.0, -100.0, 0.0, -100.0, -100.0}, ObstructionGrazing, 25.0},
+		{"Knife edge", []float64{-100.0, -100.0, 1.0, -100.0, -100.0}, ObstructionKnifeEdge, 25.0},
+		{"Obstructed", []float64{-100.0, -100.0, 2.94, -100.0, -100.0}, ObstructionBlocked, 25.0},
+		{"Skewed obstruction", []float64{-100.0, 1.0, -100.0, -100.0, -100.0}, ObstructionKnifeEdge, 12.5},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			class, point, err := ClassifyObstruction(0.0, 0.0, 50.0*M, 433*MHz, test.terrain)
+			assert.Nil(t, err)
+			assert.Equal(t, test.class, class)
+			assert.InDelta(t, float64(test.point), float64(point), allowedError)
+		})
+	}
+
+	t.Run("Rejects invalid terrain", func(t *testing.T) {
+		_, _, err := ClassifyObstruction(0.0, 0.0, 50.0*M, 433*MHz, []float64{0, 0})
+		assert.NotNil(t, err)
+
+		// Points too close to the endpoints for fresnel calculations
+		_, _, err = ClassifyObstruction(0.0, 0.0, 20.0*M, 433*MHz, obstructedTerrain)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestTerrainRoughness(t *testing.T) {
+
+	// synthesize generates a sloped profile with sinusoidal undulations of the provided amplitude
+	synthesize := func(amplitude, slope float64) []float64 {
+		terrain := make([]float64, 1001)
+		for i := range terrain {
+			terrain[i] = 100 + slope*float64(i) + amplitude*math.Sin(2*math.Pi*float64(i)/100)
+		}
+		return terrain
+	}
+
+	t.Run("Flat and uniformly sloped terrain is smooth", func(t *testing.T) {
+		assert.InDelta(t, 0.0, TerrainRoughness(synthesize(0, 0)), 1e-9)
+		assert.InDelta(t, 0.0, TerrainRoughness(synthesize(0, 0.5)), 1e-9)
+	})
+
+	t.Run("Roughness measures the interdecile range of undulations", func(t *testing.T) {
+		// The interdecile range of a sinusoid is 2·A·sin(0.4π), less a little for sampling
+		rolling := TerrainRoughness(synthesize(10, 0))
+		assert.InDelta(t, 2*10*math.Sin(0.4*math.Pi), rolling, 0.5)
+
+		// Overall slope does not contribute
+		assert.InDelta(t, rolling, TerrainRoughness(synthesize(10, 0.5)), 0.5)
+
+		mountainous := TerrainRoughness(synthesize(250, 1))
+		assert.True(t, mountainous > 20*rolling)
+	})
+
+	t.Run("Roughness ignores isolated peaks", func(t *testing.T) {
+		terrain := synthesize(0, 0)
+		terrain[500] = 500
+		assert.InDelta(t, 0.0, TerrainRoughness(terrain), 1.0)
+	})
+}
+
+func TestMeetsFresnelClearance(t *testing.T) {
+
+	// Radius of the first fresnel zone at the midpoint of a 50m path at 433MHz
+	radius, _ := FresnelPoint(25*M, 25*M, 433*MHz, 1)
+
+	tests := []struct {
+		name     string
+		height   float64
+		meets60  bool
+		meets100 bool
+	}{
+		{"Full clearance", -100.0, true, true},
+		{"First zone clearance", -3.0, true, true},
+		{"60% clearance", -1.8, true, false},
+		{"Partial clearance", -1.0, false, false},
+		{"10% clearance", -0.3, false, false},
+		{"Line of sight", 0.0, false, false},
+		{"Obstructed", 2.94, false, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			terrain := []float64{-100.0, -100.0, test.height, -100.0, -100.0}
+			clearance := -test.height / radius
+
+			ok, margin, err := MeetsFresnelClearance(0.0, 0.0, 50*M, 433*MHz, terrain, 0.6)
+			assert.Nil(t, err)
+			assert.Equal(t, test.meets60, ok)
+			assert.InDelta(t, clearance-0.6, margin, allowedError)
+
+			ok, margin, err = MeetsFresnelClearance(0.0, 0.0, 50*M, 433*MHz, terrain, 1.0)
+			assert.Nil(t, err)
+			assert.Equal(t, test.meets100, ok)
+			assert.InDelta(t, clearance-1.0, margin, allowedError)
+		})
+	}
+
+	t.Run("Rejects invalid clearance requirements", func(t *testing.T) {
+		_, _, err := MeetsFresnelClearance(0.0, 0.0, 50*M, 433*MHz, obstructedTerrain, 0)
+		assert.NotNil(t, err)
+		_, _, err = MeetsFresnelClearance(0.0, 0.0, 50*M, 433*MHz, obstructedTerrain, 1.5)
+		assert.NotNil(t, err)
+		_, _, err = MeetsFresnelClearance(0.0, 0.0, 50*M, 433*MHz, []float64{0, 0}, 0.6)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestWorstObstructionDetail(t *testing.T) {
+
+	// First fresnel zone at the midpoint of a 50m path at 433MHz
+	fresnelZone, _ := FresnelPoint(25*M, 25*M, 433*MHz, 1)
+
+	tests := []struct {
+		name      string
+		terrain   []float64
+		elevation float64
+		clearance float64
+	}{
+		{"50% impingement", []float64{-100.0, -100.0, 0.0, -100.0, -100.0}, 0.0, ObstructionClearanceFraction * fresnelZone},
+		{"100% impingement", []float64{-100.0, -100.0, 2.94, -100.0, -100.0}, 2.94, 2.94 + ObstructionClearanceFraction*fresnelZone},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			detail, err := WorstObstructionDetail(0.0, 0.0, 50*M, 433*MHz, test.terrain)
+			assert.Nil(t, err)
+
+			assert.InDelta(t, 25.0, float64(detail.Point), allowedError)
+			assert.Equal(t, test.elevation, detail.Elevation)
+			assert.InDelta(t, test.clearance, detail.ClearanceRequired, allowedError)
+
+			// Raising both ends by the required clearance raises the midpoint of the path equally
+			ok, margin, err := MeetsFresnelClearance(detail.ClearanceRequired, detail.ClearanceRequired, 50*M, 433*MHz, test.terrain, ObstructionClearanceFraction)
+			assert.Nil(t, err)
+			assert.True(t, ok)
+			assert.InDelta(t, 0.0, margin, allowedError)
+		})
+	}
+
+	t.Run("Reports no clearance for unobstructed paths", func(t *testing.T) {
+		detail, err := WorstObstructionDetail(0.0, 0.0, 50*M, 433*MHz, []float64{-100.0, -100.0, -100.0, -100.0, -100.0})
+		assert.Nil(t, err)
+		assert.Equal(t, 0.0, detail.Impingement)
+		assert.Equal(t, 0.0, detail.ClearanceRequired)
+		assert.True(t, math.IsNaN(detail.Elevation))
+
+		// Terrain below the line of sight but within the cleared fraction of the fresnel zone requires clearance
+		detail, err = WorstObstructionDetail(0.0, 0.0, 50*M, 433*MHz, []float64{-100.0, -100.0, -1.0, -100.0, -100.0})
+		assert.Nil(t, err)
+		assert.True(t, detail.Impingement > 0)
+		assert.InDelta(t, ObstructionClearanceFraction*fresnelZone-1, detail.ClearanceRequired, allowedError)
+	})
+
+	t.Run("Rejects paths too short for fresnel calculations", func(t *testing.T) {
+		_, err := WorstObstructionDetail(0.0, 0.0, 1*M, 433*MHz, []float64{0, 5, 0})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestSite(t *testing.T) {
+
+	// Terrain rising from 100m to 150m AMSL with a ridge
+	terrain := []float64{100, 105, 110, 130, 140, 150}
+
+	t.Run("Absolute height combines ground elevation and antenna height", func(t *testing.T) {
+		site := Site{GroundElevation: 100, AntennaHeight: 10}
+		assert.Equal(t, 110.0, site.AbsoluteHeight())
+
+		first, err := SiteOnTerrain(terrain, 0, 10)
+		assert.Nil(t, err)
+		assert.Equal(t, Site{GroundElevation: 100, AntennaHeight: 10}, first)
+
+		last, err := SiteOnTerrain(terrain, -1, 5)
+		assert.Nil(t, err)
+		assert.Equal(t, Site{GroundElevation: 150, AntennaHeight: 5}, last)
+
+		end, _ := SiteOnTerrain(terrain, len(terrain)-1, 5)
+		assert.Equal(t, last, end)
+	})
+
+	t.Run("Sites must lie on the terrain profile", func(t *testing.T) {
+		for _, index := range []int{len(terrain), -len(terrain) - 1} {
+			_, err := SiteOnTerrain(terrain, index, 10)
+			assert.NotNil(t, err, "index %d", index)
+		}
+
+		_, err := SiteOnTerrain([]float64{}, 0, 10)
+		assert.NotNil(t, err)
+		_, err = SiteOnTerrain([]float64{}, -1, 10)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Site paths match absolute terrain paths", func(t *testing.T) {
+		a, _ := SiteOnTerrain(terrain, 0, 10)
+		b, _ := SiteOnTerrain(terrain, -1, 5)
+
+		x, y, d2 := SiteTerrainToPathXY(a, b, 1*Km, terrain)
+		ex, ey, ed2 := TerrainToPathXY(110, 155, 1*Km, terrain)
+		assert.Equal(t, ex, x)
+		assert.Equal(t, ey, y)
+		assert.Equal(t, ed2, d2)
+
+		impingement, _ := FresnelImpingementMax(x, y, Distance(d2), 900*MHz)
+		expected, _ := FresnelImpingementMax(ex, ey, Distance(ed2), 900*MHz)
+		assert.Equal(t, expected, impingement)
+
+		// Antenna heights above ground alone would place the antennas below the terrain
+		_, wrong, _ := TerrainToPathXY(a.AntennaHeight, b.AntennaHeight, 1*Km, terrain)
+		assert.NotEqual(t, y, wrong)
+	})
+}
@@ -0,0 +1,120 @@
+package rf
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Ground reflection (two-ray) propagation helpers
+// https://en.wikipedia.org/wiki/Two-ray_ground-reflection_model
+
+// BreakpointDistance calculates the first fresnel zone breakpoint distance (4·hTx·hRx/λ) for antennas at
+// the provided heights (m) above a reflecting ground, beyond which the ground reflection begins to
+// obstruct the first fresnel zone and the path loss exponent increases
+func BreakpointDistance(hTx, hRx float64, freq Frequency) Distance {
+	wavelength := FrequencyToWavelength(freq)
+	return Distance(4 * hTx * hRx / float64(wavelength))
+}
+
+// DualSlopeLoss calculates path loss using a dual slope model, with path loss exponent n1 below the
+// breakpoint distance and n2 beyond it, anchored to the free space path loss at the breakpoint
+func DualSlopeLoss(freq Frequency, distance Distance, hTx, hRx float64, n1, n2 float64) Attenuation {
+	breakpoint := BreakpointDistance(hTx, hRx, freq)
+	anchor := CalculateFreeSpacePathLoss(freq, breakpoint)
+
+	n := n1
+	if distance > breakpoint {
+		n = n2
+	}
+
+	return anchor + Attenuation(10*n*math.Log10(float64(distance/breakpoint)))
+}
+
+// Polarization is the polarization of a wave relative to a reflecting surface
+type Polarization string
+
+// Supported polarizations
+const (
+	PolarizationHorizontal Polarization = "horizontal"
+	PolarizationVertical   Polarization = "vertical"
+)
+
+// ReflectionCoefficientGround calculates the complex Fresnel reflection coefficient of the ground for a wave
+// with the provided polarization arriving at a grazing angle (degrees above the surface), given the relative
+// permittivity and conductivity (S/m) of the ground
+// https://en.wikipedia.org/wiki/Fresnel_equations
+func ReflectionCoefficientGround(angleDeg float64, freq Frequency, groundPermittivity, groundConductivity float64, pol Polarization) complex128 {
+	ψ := angleDeg / 180 * π
+	wavelength := float64(FrequencyToWavelength(freq))
+
+	// Complex relative permittivity εr - j·60·λ·σ
+	ε := complex(groundPermittivity, -60*wavelength*groundConductivity)
+
+	sinψ := complex(math.Sin(ψ), 0)
+	root := cmplx.Sqrt(ε - complex(math.Pow(math.Cos(ψ), 2), 0))
+
+	if pol == PolarizationVertical {
+		return (ε*sinψ - root) / (ε*sinψ + root)
+	}
+	return (sinψ - root) / (sinψ + root)
+}
+
+// BrewsterAngle calculates the grazing angle (degrees above the surface) at which vertically polarized
+// waves are not reflected by a lossless ground of the provided relative permittivity. For lossy grounds
+// this approximates the pseudo-Brewster angle at which the reflection is minimised.
+// https://en.wikipedia.org/wiki/Brewster%27s_angle
+func BrewsterAngle(groundPermittivity float64) float64 {
+	return math.Atan(1/math.Sqrt(groundPermittivity)) * 180 / π
+}
+
+// TwoRayGroundLossAccurate calculates the path loss between antennas at the provided heights (m) above a flat
+// reflecting ground by coherently summing the direct and ground reflected rays, using the complex reflection
+// coefficient for the ground and polarization. Unlike the far field 40log(d) approximation this captures the
+// interference peaks and nulls at shorter distances.
+func TwoRayGroundLossAccurate(hTx, hRx float64, distance Distance, freq Frequency, groundPermittivity, groundConductivity float64, pol Polarization) Attenuation {
+	wavelength := float64(FrequencyToWavelength(freq))
+	d := float64(distance)
+
+	// Direct and reflected path lengths
+	direct := math.Sqrt(d*d + (hTx-hRx)*(hTx-hRx))
+	reflected := math.Sqrt(d*d + (hTx+hRx)*(hTx+hRx))
+
+	// Grazing angle at the reflection point
+	ψ := math.Atan2(hTx+hRx, d) * 180 / π
+	Γ := ReflectionCoefficientGround(ψ, freq, groundPermittivity, groundConductivity, pol)
+
+	// Sum the rays relative to the direct path phase
+	Δφ := PhaseDifference(Distance(reflected), Distance(direct), freq)
+	field := complex(1/direct, 0) + Γ*cmplx.Rect(1/reflected, -Δφ)
+
+	return Attenuation(-20 * math.Log10(wavelength/(4*π)*cmplx.Abs(field)))
+}
+
+// TwoRayNulls calculates the distances (in increasing order, up to maxDistance) at which the direct and ground
+// reflected rays between antennas at the provided heights (m) destructively interfere, assuming a perfectly
+// reflecting ground (Γ = -1). Nulls occur where the path difference is a whole number of wavelengths.
+func TwoRayNulls(hTx, hRx float64, freq Frequency, maxDistance Distance) []Distance {
+	wavelength := float64(FrequencyToWavelength(freq))
+	a, b := hTx+hRx, hTx-hRx
+
+	// The path difference falls from a - |b| at zero distance towards zero at long distances,
+	// so nulls are ordered by decreasing whole numbers of wavelengths
+	nulls := make([]Distance, 0)
+	for n := math.Floor((a - math.Abs(b)) / wavelength); n >= 1; n-- {
+		Δ := n * wavelength
+
+		// Solve sqrt(d² + a²) - sqrt(d² + b²) = Δ for d
+		s := (a*a - b*b - Δ*Δ) / (2 * Δ)
+		if s*s <= b*b {
+			continue
+		}
+
+		d := Distance(math.Sqrt(s*s - b*b))
+		if d > maxDistance {
+			break
+		}
+		nulls = append(nulls, d)
+	}
+
+	return nulls
+}
@@ -0,0 +1,179 @@
+package rf
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTwoRay(t *testing.T) {
+
+	t.Run("Can calculate the breakpoint distance", func(t *testing.T) {
+		d := BreakpointDistance(10, 2, 2.4*GHz)
+		assert.InDelta(t, 4*10*2/0.12492, float64(d), 0.1)
+	})
+
+	t.Run("Breakpoint distance relates to the two-ray crossover", func(t *testing.T) {
+		tests := []struct {
+			hTx, hRx float64
+			f        Frequency
+		}{
+			{10, 2, 2.4 * GHz},
+			{30, 1.5, 900 * MHz},
+			{5, 5, 433 * MHz},
+		}
+
+		for _, test := range tests {
+			breakpoint := BreakpointDistance(test.hTx, test.hRx, test.f)
+
+			// The two-ray asymptote 40log(d) - 20log(hTx·hRx) crosses free space at 4π·hTx·hRx/λ
+			crossover := Distance(math.Pi) * breakpoint
+			twoRay := 40*math.Log10(float64(crossover)) - 20*math.Log10(test.hTx*test.hRx)
+
+			assert.InDelta(t, twoRay, float64(CalculateFreeSpacePathLoss(test.f, crossover)), allowedError)
+		}
+	})
+
+	t.Run("Dual slope loss is continuous at the breakpoint", func(t *testing.T) {
+		breakpoint := BreakpointDistance(10, 2, 2.4*GHz)
+		anchor := CalculateFreeSpacePathLoss(2.4*GHz, breakpoint)
+
+		below := DualSlopeLoss(2.4*GHz, breakpoint*0.9999, 10, 2, 2, 4)
+		at := DualSlopeLoss(2.4*GHz, breakpoint, 10, 2, 2, 4)
+		above := DualSlopeLoss(2.4*GHz, breakpoint*1.0001, 10, 2, 2, 4)
+
+		assert.InDelta(t, float64(anchor), float64(at), allowedError)
+		assert.InDelta(t, float64(at), float64(below), 0.01)
+		assert.InDelta(t, float64(at), float64(above), 0.01)
+	})
+
+	t.Run("Dual slope loss uses each exponent in its region", func(t *testing.T) {
+		breakpoint := BreakpointDistance(10, 2, 2.4*GHz)
+
+		// Free space (n = 2) below the breakpoint
+		loss := DualSlopeLoss(2.4*GHz, breakpoint/10, 10, 2, 2, 4)
+		assert.InDelta(t, float64(CalculateFreeSpacePathLoss(2.4*GHz, breakpoint/10)), float64(loss), allowedError)
+
+		// 40dB per decade beyond the breakpoint
+		near := DualSlopeLoss(2.4*GHz, breakpoint*2, 10, 2, 2, 4)
+		far := DualSlopeLoss(2.4*GHz, breakpoint*20, 10, 2, 2, 4)
+		assert.InDelta(t, 40.0, float64(far-near), allowedError)
+	})
+
+	t.Run("Ground reflection approaches -1 at grazing incidence", func(t *testing.T) {
+		for _, pol := range []Polarization{PolarizationHorizontal, PolarizationVertical} {
+			Γ := ReflectionCoefficientGround(0.001, 900*MHz, 15, 0.005, pol)
+			assert.InDelta(t, -1.0, real(Γ), 0.001, "%s", pol)
+			assert.InDelta(t, 0.0, imag(Γ), 0.001, "%s", pol)
+		}
+	})
+
+	t.Run("Vertical polarization is not reflected at the Brewster angle", func(t *testing.T) {
+		brewster := BrewsterAngle(15)
+		assert.InDelta(t, 14.48, brewster, 0.01)
+
+		// Lossless ground
+		Γ := ReflectionCoefficientGround(brewster, 900*MHz, 15, 0, PolarizationVertical)
+		assert.InDelta(t, 0.0, cmplx.Abs(Γ), allowedError)
+
+		// Horizontal polarization is still reflected
+		Γ = ReflectionCoefficientGround(brewster, 900*MHz, 15, 0, PolarizationHorizontal)
+		assert.True(t, cmplx.Abs(Γ) > 0.5)
+	})
+
+	t.Run("Lossy ground has a reflection minimum near the pseudo-Brewster angle", func(t *testing.T) {
+		brewster := BrewsterAngle(15)
+
+		minimum := ReflectionCoefficientGround(brewster, 900*MHz, 15, 0.005, PolarizationVertical)
+		assert.True(t, cmplx.Abs(minimum) < 0.1)
+
+		for _, angle := range []float64{brewster / 2, brewster * 2} {
+			Γ := ReflectionCoefficientGround(angle, 900*MHz, 15, 0.005, PolarizationVertical)
+			assert.True(t, cmplx.Abs(Γ) > cmplx.Abs(minimum), "angle %.2f", angle)
+		}
+	})
+
+	t.Run("Accurate two-ray loss oscillates before the breakpoint", func(t *testing.T) {
+		breakpoint := float64(BreakpointDistance(10, 2, 900*MHz))
+
+		// Count local minima and maxima in the received power over distance
+		extrema, previous, rising := 0, 0.0, false
+		for i := 0; i < 2000; i++ {
+			d := Distance(10 + float64(i)*breakpoint/2000)
+			loss := float64(TwoRayGroundLossAccurate(10, 2, d, 900*MHz, 15, 0.005, PolarizationHorizontal))
+
+			if i > 1 && (loss > previous) != rising {
+				extrema++
+			}
+			if i > 0 {
+				rising = loss > previous
+			}
+			previous = loss
+		}
+
+		assert.True(t, extrema >= 4, "extrema: %d", extrema)
+	})
+
+	t.Run("Accurate two-ray loss has nulls deeper than free space", func(t *testing.T) {
+		worst := 0.0
+		for d := 20.0; d < 200; d += 0.1 {
+			excess := float64(TwoRayGroundLossAccurate(10, 2, Distance(d), 900*MHz, 15, 0.005, PolarizationHorizontal) - CalculateFreeSpacePathLoss(900*MHz, Distance(d)))
+			worst = math.Max(worst, excess)
+		}
+		assert.True(t, worst > 15, "worst excess loss: %.2f", worst)
+	})
+
+	t.Run("Accurate two-ray loss approaches the far field asymptote", func(t *testing.T) {
+		for _, d := range []Distance{20 * Km, 50 * Km} {
+			asymptote := 40*math.Log10(float64(d)) - 20*math.Log10(10*2)
+			loss := TwoRayGroundLossAccurate(10, 2, d, 900*MHz, 15, 0.005, PolarizationHorizontal)
+			assert.InDelta(t, asymptote, float64(loss), 0.5, "distance %.0f", d)
+		}
+	})
+
+	t.Run("Two-ray nulls occur at whole wavelength path differences", func(t *testing.T) {
+		λ := float64(FrequencyToWavelength(900 * MHz))
+		nulls := TwoRayNulls(10, 2, 900*MHz, 10*Km)
+
+		// Path difference starts at 2·hRx = 4m, so floor(4/λ) nulls
+		assert.Len(t, nulls, int(math.Floor(4/λ)))
+
+		for i, d := range nulls {
+			if i > 0 {
+				assert.True(t, d > nulls[i-1])
+			}
+
+			Δ := math.Sqrt(float64(d*d)+12*12) - math.Sqrt(float64(d*d)+8*8)
+			assert.InDelta(t, 0.0, math.Remainder(Δ, λ), allowedError, "distance %.2f", d)
+		}
+	})
+
+	t.Run("Two-ray null spacing matches the far field geometry", func(t *testing.T) {
+		λ := float64(FrequencyToWavelength(900 * MHz))
+		nulls := TwoRayNulls(10, 2, 900*MHz, 10*Km)
+
+		// At long range the path difference approaches 2·hTx·hRx/d, so the n'th null from the
+		// outermost lies at 2·hTx·hRx/(n·λ)
+		for n := 1; n <= 2; n++ {
+			d := float64(nulls[len(nulls)-n])
+			assert.InDelta(t, 2*10*2/(float64(n)*λ), d, d*0.02, "null %d", n)
+		}
+	})
+
+	t.Run("Two-ray nulls are deep fades in the accurate model", func(t *testing.T) {
+		nulls := TwoRayNulls(10, 2, 900*MHz, 1*Km)
+		outer := nulls[len(nulls)-1]
+
+		null := TwoRayGroundLossAccurate(10, 2, outer, 900*MHz, 15, 0.005, PolarizationHorizontal)
+		fsl := CalculateFreeSpacePathLoss(900*MHz, outer)
+		assert.True(t, null-fsl > 10, "excess loss: %.2f", null-fsl)
+	})
+
+	t.Run("Two-ray nulls are limited by distance", func(t *testing.T) {
+		all := TwoRayNulls(10, 2, 900*MHz, 10*Km)
+		limited := TwoRayNulls(10, 2, 900*MHz, all[len(all)-1]-1)
+		assert.Len(t, limited, len(all)-1)
+	})
+}
@@ -0,0 +1,55 @@
+package rf
+
+// Wideband and multi-carrier helpers
+
+// OFDMSubcarrierFreqs calculates the absolute frequencies of OFDM subcarriers centred around a carrier.
+// Subcarriers are indexed as FFT bins, so odd counts are symmetric about the carrier and even counts
+// have one more subcarrier below the carrier than above. The DC (carrier) subcarrier is included,
+// see OFDMSubcarrierFreqsSkipDC for systems where it is left unused. Returns nil for non-positive counts.
+// https://en.wikipedia.org/wiki/Orthogonal_frequency-division_multiplexing
+func OFDMSubcarrierFreqs(centerFreq Frequency, subcarrierSpacing Frequency, numSubcarriers int) []Frequency {
+	if numSubcarriers <= 0 {
+		return nil
+	}
+
+	freqs := make([]Frequency, numSubcarriers)
+	first := -numSubcarriers / 2
+
+	for i := range freqs {
+		freqs[i] = centerFreq + Frequency(first+i)*subcarrierSpacing
+	}
+
+	return freqs
+}
+
+// OFDMSubcarrierFreqsSkipDC calculates the absolute frequencies of OFDM subcarriers centred around a carrier
+// with the DC (carrier) subcarrier left unused. Even counts are symmetric about the carrier and
+// odd counts have one more subcarrier below the carrier than above. Returns nil for non-positive counts.
+func OFDMSubcarrierFreqsSkipDC(centerFreq Frequency, subcarrierSpacing Frequency, numSubcarriers int) []Frequency {
+	if numSubcarriers <= 0 {
+		return nil
+	}
+
+	freqs := make([]Frequency, numSubcarriers)
+	first := -(numSubcarriers + 1) / 2
+
+	for i := range freqs {
+		index := first + i
+		if index >= 0 {
+			index++
+		}
+		freqs[i] = centerFreq + Frequency(index)*subcarrierSpacing
+	}
+
+	return freqs
+}
+
+// FrequencySelectiveLoss calculates the free space path loss at each of the provided frequencies over a distance,
+// for example across the subcarriers of a wideband signal from OFDMSubcarrierFreqs
+func FrequencySelectiveLoss(freqs []Frequency, distance Distance) []Attenuation {
+	losses := make([]Attenuation, len(freqs))
+	for i, f := range freqs {
+		losses[i] = CalculateFreeSpacePathLoss(f, distance)
+	}
+	return losses
+}
@@ -0,0 +1,70 @@
+package rf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWideband(t *testing.T) {
+
+	t.Run("Can plan OFDM subcarriers with an odd count", func(t *testing.T) {
+		freqs := OFDMSubcarrierFreqs(2.4*GHz, 312.5*KHz, 5)
+		expected := []Frequency{2.4*GHz - 625*KHz, 2.4*GHz - 312.5*KHz, 2.4 * GHz, 2.4*GHz + 312.5*KHz, 2.4*GHz + 625*KHz}
+
+		assert.Len(t, freqs, len(expected))
+		for i := range expected {
+			assert.InDelta(t, float64(expected[i]), float64(freqs[i]), allowedError, "index %d", i)
+		}
+	})
+
+	t.Run("Can plan OFDM subcarriers with an even count", func(t *testing.T) {
+		freqs := OFDMSubcarrierFreqs(2.4*GHz, 312.5*KHz, 4)
+		expected := []Frequency{2.4*GHz - 625*KHz, 2.4*GHz - 312.5*KHz, 2.4 * GHz, 2.4*GHz + 312.5*KHz}
+
+		assert.Len(t, freqs, len(expected))
+		for i := range expected {
+			assert.InDelta(t, float64(expected[i]), float64(freqs[i]), allowedError, "index %d", i)
+		}
+	})
+
+	t.Run("Can plan OFDM subcarriers skipping DC", func(t *testing.T) {
+		freqs := OFDMSubcarrierFreqsSkipDC(2.4*GHz, 312.5*KHz, 4)
+		expected := []Frequency{2.4*GHz - 625*KHz, 2.4*GHz - 312.5*KHz, 2.4*GHz + 312.5*KHz, 2.4*GHz + 625*KHz}
+
+		assert.Len(t, freqs, len(expected))
+		for i := range expected {
+			assert.InDelta(t, float64(expected[i]), float64(freqs[i]), allowedError, "index %d", i)
+		}
+
+		freqs = OFDMSubcarrierFreqsSkipDC(2.4*GHz, 312.5*KHz, 3)
+		expected = []Frequency{2.4*GHz - 625*KHz, 2.4*GHz - 312.5*KHz, 2.4*GHz + 312.5*KHz}
+
+		assert.Len(t, freqs, len(expected))
+		for i := range expected {
+			assert.InDelta(t, float64(expected[i]), float64(freqs[i]), allowedError, "index %d", i)
+		}
+	})
+
+	t.Run("Rejects non-positive subcarrier counts", func(t *testing.T) {
+		assert.Nil(t, OFDMSubcarrierFreqs(2.4*GHz, 312.5*KHz, 0))
+		assert.Nil(t, OFDMSubcarrierFreqs(2.4*GHz, 312.5*KHz, -4))
+		assert.Nil(t, OFDMSubcarrierFreqsSkipDC(2.4*GHz, 312.5*KHz, -4))
+	})
+
+	t.Run("Frequency selective loss increases across the band", func(t *testing.T) {
+		freqs := OFDMSubcarrierFreqs(5.5*GHz, 78.125*KHz, 2048)
+		losses := FrequencySelectiveLoss(freqs, 1*Km)
+
+		assert.Len(t, losses, len(freqs))
+		for i := range losses {
+			assert.InDelta(t, float64(CalculateFreeSpacePathLoss(freqs[i], 1*Km)), float64(losses[i]), allowedError)
+			if i > 0 {
+				assert.True(t, losses[i] > losses[i-1], "index %d", i)
+			}
+		}
+
+		// 160MHz of bandwidth at 5.5GHz spans ~0.25dB
+		assert.InDelta(t, 0.25, float64(losses[len(losses)-1]-losses[0]), 0.01)
+	})
+}